@@ -38,18 +38,25 @@ func main() {
 		log.Fatal("failed to initialize token maker", zap.Error(err))
 	}
 
+	// Initialize Ticket Signer (PASETO v2.public, base64 ed25519 seed)
+	ticketSigner, err := token.NewTicketSigner(viperConfig.GetString("token.ticket_key"))
+	if err != nil {
+		log.Fatal("failed to initialize ticket signer", zap.Error(err))
+	}
+
 	// Initialize Redis Client
 	redisClient := config.NewRedisClient(viperConfig, log)
 
 	// Bootstrap the application
 	config.Boostrap(config.BootstrapConfig{
-		DB:          db,
-		App:         app,
-		Log:         log,
-		Validate:    validate,
-		Config:      viperConfig,
-		TokenMaker:  tokenMaker,
-		RedisClient: redisClient,
+		DB:           db,
+		App:          app,
+		Log:          log,
+		Validate:     validate,
+		Config:       viperConfig,
+		TokenMaker:   tokenMaker,
+		TicketSigner: ticketSigner,
+		RedisClient:  redisClient,
 	})
 
 	// Start the Web Server
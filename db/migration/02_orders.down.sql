@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_reservation_order;
+ALTER TABLE reservations DROP COLUMN IF EXISTS order_id;
+DROP TABLE IF EXISTS orders;
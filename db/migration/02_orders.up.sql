@@ -0,0 +1,16 @@
+-- 🧾 Orders Table (groups reservations booked together in one request)
+CREATE TABLE orders (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  total_price BIGINT NOT NULL,
+  expires_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE reservations ADD COLUMN order_id UUID;
+
+ALTER TABLE reservations
+ADD FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_reservation_order
+ON reservations (order_id);
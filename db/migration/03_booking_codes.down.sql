@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_payment_order;
+DELETE FROM payments WHERE reservation_id IS NULL;
+ALTER TABLE payments ALTER COLUMN reservation_id SET NOT NULL;
+ALTER TABLE payments DROP COLUMN IF EXISTS order_id;
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_booking_code_key;
+ALTER TABLE orders DROP COLUMN IF EXISTS booking_code;
+ALTER TABLE reservations ALTER COLUMN order_id DROP NOT NULL;
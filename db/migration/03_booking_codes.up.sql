@@ -0,0 +1,62 @@
+-- every reservation made before orders existed gets an order of its own
+WITH legacy AS (
+  SELECT id AS reservation_id, uuid_generate_v4() AS order_id, COALESCE(price, 0) AS total_price, expires_at
+  FROM reservations
+  WHERE order_id IS NULL
+), created AS (
+  INSERT INTO orders (id, total_price, expires_at)
+  SELECT order_id, total_price, expires_at FROM legacy
+  RETURNING id
+)
+UPDATE reservations r
+SET order_id = l.order_id
+FROM legacy l
+WHERE r.id = l.reservation_id;
+
+ALTER TABLE reservations ALTER COLUMN order_id SET NOT NULL;
+
+-- 🔖 short human friendly booking reference (PNR)
+ALTER TABLE orders ADD COLUMN booking_code VARCHAR(6);
+ALTER TABLE orders ADD CONSTRAINT orders_booking_code_key UNIQUE (booking_code);
+
+-- existing orders draw their code from the same alphabet as new ones, retrying on a collision
+DO $$
+DECLARE
+  alphabet CONSTANT TEXT := 'ABCDEFGHJKLMNPQRSTUVWXYZ23456789';
+  order_id UUID;
+  code TEXT;
+BEGIN
+  FOR order_id IN SELECT id FROM orders WHERE booking_code IS NULL LOOP
+    LOOP
+      code := '';
+      FOR i IN 1..6 LOOP
+        code := code || SUBSTR(alphabet, 1 + FLOOR(RANDOM() * LENGTH(alphabet))::INT, 1);
+      END LOOP;
+      BEGIN
+        UPDATE orders SET booking_code = code WHERE id = order_id;
+        EXIT;
+      EXCEPTION WHEN unique_violation THEN
+        -- taken, draw another code
+      END;
+    END LOOP;
+  END LOOP;
+END $$;
+
+ALTER TABLE orders ALTER COLUMN booking_code SET NOT NULL;
+
+-- 💳 payments belong to an order, either for one reservation or for the whole order
+ALTER TABLE payments ADD COLUMN order_id UUID;
+
+UPDATE payments p
+SET order_id = r.order_id
+FROM reservations r
+WHERE p.reservation_id = r.id;
+
+ALTER TABLE payments ALTER COLUMN order_id SET NOT NULL;
+ALTER TABLE payments ALTER COLUMN reservation_id DROP NOT NULL;
+
+ALTER TABLE payments
+ADD FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_payment_order
+ON payments (order_id);
@@ -0,0 +1 @@
+ALTER TABLE schedules ADD COLUMN available_seats INT NOT NULL DEFAULT 0;
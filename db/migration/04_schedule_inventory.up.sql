@@ -0,0 +1,8 @@
+-- 🎫 availability is derived from seats and active reservations per schedule
+ALTER TABLE schedules DROP COLUMN available_seats;
+
+-- undo the decrements previously applied to wagons on every payment
+UPDATE wagons w
+SET total_seats = c.seat_count, updated_at = NOW()
+FROM (SELECT wagon_id, COUNT(*) AS seat_count FROM seats GROUP BY wagon_id) c
+WHERE c.wagon_id = w.id AND w.total_seats < c.seat_count;
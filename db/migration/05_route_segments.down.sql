@@ -0,0 +1,22 @@
+ALTER TABLE reservations DROP CONSTRAINT IF EXISTS exclude_overlapping_seat_reservation;
+
+-- only one active reservation per seat can survive the old index
+UPDATE reservations r
+SET reservation_status = 'cancelled', updated_at = NOW()
+WHERE reservation_status IN ('pending', 'success')
+  AND EXISTS (
+    SELECT 1 FROM reservations o
+    WHERE o.schedule_id = r.schedule_id AND o.wagon_id = r.wagon_id AND o.seat_id = r.seat_id
+      AND o.reservation_status IN ('pending', 'success')
+      AND o.created_at < r.created_at
+  );
+
+CREATE UNIQUE INDEX unique_active_seat_reservation
+ON reservations (schedule_id, wagon_id, seat_id)
+WHERE reservation_status IN ('pending', 'success');
+
+ALTER TABLE reservations DROP CONSTRAINT IF EXISTS reservation_stops_check;
+ALTER TABLE reservations DROP COLUMN IF EXISTS to_stop;
+ALTER TABLE reservations DROP COLUMN IF EXISTS from_stop;
+
+DROP TABLE IF EXISTS route_stops;
@@ -0,0 +1,31 @@
+-- 🚏 ordered stops of a route, from the source station (stop 0) to the destination station.
+-- routes without stops are served as a single leg between source and destination.
+CREATE TABLE route_stops (
+  id BIGSERIAL PRIMARY KEY,
+  route_id BIGINT NOT NULL,
+  stop_order INT NOT NULL,
+  station_code VARCHAR(4) NOT NULL,
+  minutes_from_start INT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (route_id, stop_order),
+  FOREIGN KEY (route_id) REFERENCES routes(id) ON DELETE CASCADE,
+  FOREIGN KEY (station_code) REFERENCES stations(code) ON DELETE CASCADE
+);
+
+-- a reservation occupies the legs between from_stop and to_stop, [from_stop, to_stop)
+ALTER TABLE reservations ADD COLUMN from_stop INT NOT NULL DEFAULT 0;
+ALTER TABLE reservations ADD COLUMN to_stop INT NOT NULL DEFAULT 1;
+ALTER TABLE reservations ADD CONSTRAINT reservation_stops_check CHECK (from_stop < to_stop);
+
+-- a seat can be sold again for legs that do not overlap
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+DROP INDEX IF EXISTS unique_active_seat_reservation;
+
+ALTER TABLE reservations ADD CONSTRAINT exclude_overlapping_seat_reservation
+EXCLUDE USING gist (
+  schedule_id WITH =,
+  wagon_id WITH =,
+  seat_id WITH =,
+  int4range(from_stop, to_stop) WITH &&
+) WHERE (reservation_status IN ('pending', 'success'));
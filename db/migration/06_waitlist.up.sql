@@ -0,0 +1,28 @@
+-- ⏳ passengers queueing for a sold out schedule class
+CREATE TYPE waitlist_status AS ENUM ('waiting', 'promoted', 'cancelled');
+
+CREATE TABLE waitlist_entries (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  passenger_id UUID NOT NULL,
+  schedule_id BIGINT NOT NULL,
+  class_type tipe_class NOT NULL,
+  from_stop INT NOT NULL DEFAULT 0,
+  to_stop INT NOT NULL DEFAULT 1,
+  status waitlist_status NOT NULL DEFAULT 'waiting',
+  reservation_id UUID,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CHECK (from_stop < to_stop),
+  FOREIGN KEY (passenger_id) REFERENCES passengers(id) ON DELETE CASCADE,
+  FOREIGN KEY (schedule_id) REFERENCES schedules(id) ON DELETE CASCADE,
+  FOREIGN KEY (reservation_id) REFERENCES reservations(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_waitlist_queue
+ON waitlist_entries (schedule_id, class_type, created_at)
+WHERE status = 'waiting';
+
+-- a passenger waits at most once per schedule
+CREATE UNIQUE INDEX unique_waiting_passenger
+ON waitlist_entries (passenger_id, schedule_id)
+WHERE status = 'waiting';
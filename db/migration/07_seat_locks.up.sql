@@ -0,0 +1,11 @@
+-- 🔒 seat locks for deployments that keep them in postgres instead of redis
+CREATE TABLE seat_locks (
+  schedule_id BIGINT NOT NULL,
+  wagon_id BIGINT NOT NULL,
+  seat_id BIGINT NOT NULL,
+  owner VARCHAR NOT NULL,
+  expires_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (schedule_id, wagon_id, seat_id),
+  FOREIGN KEY (schedule_id) REFERENCES schedules(id) ON DELETE CASCADE
+);
@@ -0,0 +1,10 @@
+-- 🔁 links a reservation to the one it was exchanged for
+CREATE TABLE reservation_exchanges (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  original_reservation_id UUID NOT NULL UNIQUE,
+  new_reservation_id UUID NOT NULL UNIQUE,
+  fare_difference BIGINT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (original_reservation_id) REFERENCES reservations(id) ON DELETE CASCADE,
+  FOREIGN KEY (new_reservation_id) REFERENCES reservations(id) ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS refunds;
+
+-- postgres cannot drop an enum value, refunded reservations fall back to cancelled
+UPDATE reservations SET reservation_status = 'cancelled' WHERE reservation_status = 'refunded';
@@ -0,0 +1,16 @@
+-- 💸 refunds of paid reservations
+ALTER TYPE status_reservation ADD VALUE IF NOT EXISTS 'refunded';
+
+CREATE TABLE refunds (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  reservation_id UUID NOT NULL UNIQUE,
+  payment_id UUID,
+  paid_amount BIGINT NOT NULL,
+  fee_percent INT NOT NULL CHECK (fee_percent BETWEEN 0 AND 100),
+  fee BIGINT NOT NULL,
+  amount BIGINT NOT NULL CHECK (amount >= 0),
+  reason TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (reservation_id) REFERENCES reservations(id) ON DELETE CASCADE,
+  FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE SET NULL
+);
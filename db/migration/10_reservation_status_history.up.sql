@@ -0,0 +1,13 @@
+-- 📜 audit trail of every reservation status change
+CREATE TABLE reservation_status_history (
+  id BIGSERIAL PRIMARY KEY,
+  reservation_id UUID NOT NULL,
+  from_status status_reservation,
+  to_status status_reservation NOT NULL,
+  reason TEXT NOT NULL,
+  actor VARCHAR(100) NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (reservation_id) REFERENCES reservations(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_reservation_status_history_reservation ON reservation_status_history(reservation_id, created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_passengers_session;
+
+ALTER TABLE passengers DROP COLUMN IF EXISTS session_id;
@@ -0,0 +1,4 @@
+-- 🔐 passengers added by a guest belong to the guest session that created them
+ALTER TABLE passengers ADD COLUMN session_id VARCHAR(64);
+
+CREATE INDEX idx_passengers_session ON passengers(session_id);
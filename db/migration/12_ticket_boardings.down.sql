@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS ticket_boardings;
+
+-- postgres cannot drop an enum value, conductors fall back to users
+UPDATE users SET role = 'user' WHERE role = 'conductor';
@@ -0,0 +1,16 @@
+-- 🎫 conductors check signed tickets on board
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'conductor';
+
+-- 🚪 a ticket can only be scanned for boarding once
+CREATE TABLE ticket_boardings (
+  id BIGSERIAL PRIMARY KEY,
+  reservation_id UUID NOT NULL UNIQUE,
+  schedule_id BIGINT NOT NULL,
+  conductor_id UUID,
+  boarded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (reservation_id) REFERENCES reservations(id) ON DELETE CASCADE,
+  FOREIGN KEY (schedule_id) REFERENCES schedules(id) ON DELETE CASCADE,
+  FOREIGN KEY (conductor_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_ticket_boardings_schedule ON ticket_boardings(schedule_id);
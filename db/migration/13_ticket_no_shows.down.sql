@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_ticket_boardings_status;
+
+-- only tickets that were actually boarded are kept
+DELETE FROM ticket_boardings WHERE status <> 'boarded';
+
+ALTER TABLE ticket_boardings
+  DROP COLUMN IF EXISTS updated_at,
+  DROP COLUMN IF EXISTS checked_in_at,
+  DROP COLUMN IF EXISTS status,
+  ALTER COLUMN boarded_at SET DEFAULT CURRENT_TIMESTAMP,
+  ALTER COLUMN boarded_at SET NOT NULL;
+
+DROP TYPE IF EXISTS boarding_status;
@@ -0,0 +1,13 @@
+-- 🧳 a ticket is checked in by its passenger, boarded by a conductor, or a no-show once the train left
+CREATE TYPE boarding_status AS ENUM ('checked_in', 'boarded', 'no_show');
+
+ALTER TABLE ticket_boardings
+  ALTER COLUMN boarded_at DROP NOT NULL,
+  ALTER COLUMN boarded_at DROP DEFAULT,
+  ADD COLUMN status boarding_status NOT NULL DEFAULT 'boarded',
+  ADD COLUMN checked_in_at TIMESTAMP,
+  ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+ALTER TABLE ticket_boardings ALTER COLUMN status DROP DEFAULT;
+
+CREATE INDEX idx_ticket_boardings_status ON ticket_boardings(schedule_id, status);
@@ -0,0 +1,2 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS contact_phone;
+ALTER TABLE orders DROP COLUMN IF EXISTS contact_email;
@@ -0,0 +1,3 @@
+-- 📇 contact of the booker, guests retrieve their booking with it and the booking code
+ALTER TABLE orders ADD COLUMN contact_email VARCHAR(255);
+ALTER TABLE orders ADD COLUMN contact_phone VARCHAR(50);
@@ -0,0 +1,2 @@
+-- 🧾 the passenger type a ticket was priced for, exchanges keep it
+ALTER TABLE reservations ADD COLUMN passenger_type VARCHAR(20) NOT NULL DEFAULT 'adult';
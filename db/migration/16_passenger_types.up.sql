@@ -0,0 +1,2 @@
+-- 🎫 the passenger type a passenger travels as, reductions are only granted to verified passengers
+ALTER TABLE passengers ADD COLUMN passenger_type VARCHAR(20) NOT NULL DEFAULT 'adult';
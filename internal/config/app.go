@@ -7,6 +7,7 @@ import (
 	"railway-go/internal/delivery/http/route"
 	"railway-go/internal/repository"
 	"railway-go/internal/usecase"
+	"railway-go/internal/utils/notification"
 	"railway-go/internal/utils/token"
 	"time"
 
@@ -19,21 +20,25 @@ import (
 )
 
 type BootstrapConfig struct {
-	DB          *pgxpool.Pool
-	App         *fiber.App
-	Log         *zap.Logger
-	Validate    *validator.Validate
-	Config      *viper.Viper
-	TokenMaker  token.Maker
-	RedisClient *redis.Client
+	DB           *pgxpool.Pool
+	App          *fiber.App
+	Log          *zap.Logger
+	Validate     *validator.Validate
+	Config       *viper.Viper
+	TokenMaker   token.Maker
+	TicketSigner token.TicketSigner
+	RedisClient  *redis.Client
 }
 
 // do bootstrap here
 func Boostrap(config BootstrapConfig) {
 	// setup repositories
-	repo := repository.NewStore(config.DB, config.RedisClient)
+	seatLocker := NewSeatLocker(config.Config, config.DB, config.RedisClient, config.Log)
+	repo := repository.NewStore(config.DB, config.RedisClient, seatLocker)
 
-	baseUsecase := usecase.NewUseCase(repo, config.Log, config.Validate)
+	pricing := usecase.NewPricingConfig(config.Config, config.Log)
+	baseUsecase := usecase.NewUseCase(repo, config.Log, config.Validate, pricing)
+	notifier := notification.NewLogNotifier(config.Log)
 
 	// setup usecases
 	userSessionUC := usecase.NewUserSessionUsecase(baseUsecase, config.TokenMaker, config.Config)
@@ -47,8 +52,17 @@ func Boostrap(config BootstrapConfig) {
 	trainUC := usecase.NewTrainUsecase(baseUsecase)
 	wagonUC := usecase.NewWagonUsecase(baseUsecase)
 	stationUC := usecase.NewStationUsecase(baseUsecase)
+	orderUC := usecase.NewOrderUsecase(baseUsecase, notifier)
+	waitlistUC := usecase.NewWaitlistUsecase(baseUsecase)
+	holdUC := usecase.NewHoldUsecase(baseUsecase)
+	quoteUC := usecase.NewQuoteUsecase(baseUsecase)
+	refundUC := usecase.NewRefundUsecase(baseUsecase, config.Config)
+	idempotencyUC := usecase.NewIdempotencyUsecase(baseUsecase)
+	journeyUC := usecase.NewJourneyUsecase(baseUsecase, config.Config)
+	ticketUC := usecase.NewTicketUsecase(baseUsecase, config.TicketSigner, config.Config)
 
 	StartReservationCleanup(reservationUC, paymentUC, config.Log)
+	StartNoShowProcessing(ticketUC, config.Log)
 	// setup controlers
 	userSesionController := http.NewUserSessionController(userSessionUC, config.Log)
 	reservationController := http.NewReservationController(reservationUC, config.Log, userSessionUC)
@@ -61,9 +75,17 @@ func Boostrap(config BootstrapConfig) {
 	trainController := http.NewTrainController(trainUC, config.Log)
 	wagonController := http.NewWagonController(config.Log, wagonUC)
 	stationController := http.NewStationController(stationUC, config.Log)
+	orderController := http.NewOrderController(orderUC, config.Log)
+	waitlistController := http.NewWaitlistController(waitlistUC, config.Log)
+	holdController := http.NewHoldController(holdUC, config.Log)
+	quoteController := http.NewQuoteController(quoteUC, config.Log)
+	refundController := http.NewRefundController(refundUC, config.Log)
+	journeyController := http.NewJourneyController(journeyUC, config.Log)
+	ticketController := http.NewTicketController(ticketUC, config.Log)
 
 	// setup middlewares
 	userSessionMiddlewares := middleware.NewAuthMiddleware(userSessionUC, config.TokenMaker)
+	idempotencyMiddleware := middleware.NewIdempotencyMiddleware(idempotencyUC, config.Log)
 
 	// setup routes
 	routeConfig := route.RouteConfig{
@@ -79,7 +101,15 @@ func Boostrap(config BootstrapConfig) {
 		TrainController:       trainController,
 		WagonController:       wagonController,
 		StationController:     stationController,
+		OrderController:       orderController,
+		WaitlistController:    waitlistController,
+		HoldController:        holdController,
+		QuoteController:       quoteController,
+		RefundController:      refundController,
+		JourneyController:     journeyController,
+		TicketController:      ticketController,
 		AuthMiddleware:        userSessionMiddlewares,
+		IdempotencyMiddleware: idempotencyMiddleware,
 	}
 
 	routeConfig.Setup()
@@ -99,9 +129,28 @@ func StartReservationCleanup(reservationUC usecase.ReservationUC, paymentUC usec
 			}
 			err = reservationUC.AutoDeleteReservations(ctx)
 			if err != nil {
-				log.Error("failed to auto delete expired reservations", zap.Error(err))
+				log.Error("failed to auto expire unpaid reservations", zap.Error(err))
 			} else {
-				log.Info("successfully auto deleted expired reservations")
+				log.Info("successfully auto expired unpaid reservations")
+			}
+			if err := reservationUC.PurgeSeatLocks(ctx); err != nil {
+				log.Error("failed to purge expired seat locks", zap.Error(err))
+			}
+			cancel()
+		}
+	}()
+}
+
+// StartNoShowProcessing marks the tickets of departed trips that were never scanned on board as no-shows.
+func StartNoShowProcessing(ticketUC usecase.TicketUC, log *zap.Logger) {
+	go func() {
+		ticker := time.NewTicker(5 * time.Minute)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if err := ticketUC.AutoMarkNoShows(ctx); err != nil {
+				log.Error("failed to mark no-shows", zap.Error(err))
 			}
 			cancel()
 		}
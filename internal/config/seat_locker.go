@@ -0,0 +1,29 @@
+package config
+
+import (
+	"railway-go/internal/repository"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// NewSeatLocker picks where seat locks are kept from seat_lock.backend:
+// "redis" (the default), "postgres" or "memory".
+func NewSeatLocker(v *viper.Viper, db *pgxpool.Pool, redisClient *redis.Client, log *zap.Logger) repository.SeatLocker {
+	backend := v.GetString("seat_lock.backend")
+
+	switch backend {
+	case "", "redis":
+		return repository.NewRedisSeatLocker(redisClient)
+	case "postgres":
+		return repository.NewPostgresSeatLocker(db)
+	case "memory":
+		log.Warn("seat locks are kept in memory and are not shared between instances")
+		return repository.NewMemorySeatLocker()
+	default:
+		log.Sugar().Fatalf("unknown seat lock backend: %s", backend)
+		return nil
+	}
+}
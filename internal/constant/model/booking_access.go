@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+const (
+	// BookingVerificationTTL is how long a verification code sent for a booking can be used
+	BookingVerificationTTL = 10 * time.Minute
+	// BookingVerificationCooldown is how long a booking code and email wait before another code is requested
+	BookingVerificationCooldown = time.Minute
+	// BookingVerificationWindow is how long the attempts and the codes sent for a booking are counted,
+	// longer than a code lives so asking for a new code does not bring new attempts
+	BookingVerificationWindow = time.Hour
+	// BookingVerificationAttempts is how many attempts a booking gets per window, across all codes sent for it
+	BookingVerificationAttempts = 5
+	// BookingVerificationSends is how many codes are sent for a booking per window
+	BookingVerificationSends = 5
+)
+
+// BookingVerification is a one-time code sent to the contact of a booking. Only its hash is
+// kept, which keeps the code out of plain sight but does not protect it: six digits are
+// found from the hash in no time, the short expiry and the attempt limit are what protect it.
+type BookingVerification struct {
+	CodeHash string    `json:"code_hash"`
+	SentAt   time.Time `json:"sent_at"`
+}
+
+// BookingContactRequest is the contact a guest retrieves the booking with later
+type BookingContactRequest struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+	Phone string `json:"phone" validate:"omitempty,max=50"`
+}
+
+type BookingContactResponse struct {
+	BookingCode string `json:"booking_code"`
+	Email       string `json:"email"`
+	Phone       string `json:"phone,omitempty"`
+}
+
+// BookingAccessRequest asks for a verification code to retrieve a booking
+type BookingAccessRequest struct {
+	BookingCode string `json:"booking_code" validate:"required,len=6"`
+	Email       string `json:"email" validate:"required,email,max=255"`
+}
+
+// BookingVerifyRequest grants the session access to a booking with the code that was sent
+type BookingVerifyRequest struct {
+	BookingCode string `json:"booking_code" validate:"required,len=6"`
+	Email       string `json:"email" validate:"required,email,max=255"`
+	Code        string `json:"code" validate:"required,len=6,numeric"`
+}
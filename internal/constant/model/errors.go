@@ -10,6 +10,10 @@ var (
 	ErrSeatAlreadyLocked          = errors.New("seat is already locked")
 	ErrSeatNotLocked              = errors.New("seat is not locked")
 	ErrSeatNotFound               = errors.New("seat not found")
+	ErrHoldNotFound               = errors.New("seat hold not found or expired")
+	ErrIdempotencyKeyNotFound     = errors.New("idempotency key not found or expired")
+	ErrVerificationNotFound       = errors.New("verification code not found or expired")
+	ErrQuoteNotFound              = errors.New("quote not found or expired")
 	ErrReservationNotFound        = errors.New("reservation not found")
 	ErrReservationAlreadyExist    = errors.New("reservation already exist")
 	ErrReservationNotAvailable    = errors.New("reservation not available")
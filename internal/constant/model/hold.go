@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+const (
+	// ReservationTTL is how long a pending reservation lives before payment. The lock taken
+	// while booking it is released on commit and only expires after ReservationTTL when the
+	// booking process dies in between.
+	ReservationTTL = 15 * time.Minute
+	// HoldTTL is how long a seat hold lives, and how far each extension pushes it
+	HoldTTL = 5 * time.Minute
+	// HoldMaxLifetime caps how long a hold can be kept alive through extensions
+	HoldMaxLifetime = 15 * time.Minute
+)
+
+type HoldRequest struct {
+	ScheduleID  int64  `json:"schedule_id" validate:"required"`
+	WagonID     int64  `json:"wagon_id" validate:"required"`
+	SeatID      int64  `json:"seat_id" validate:"required"`
+	FromStation string `json:"from_station" validate:"max=4"`
+	ToStation   string `json:"to_station" validate:"max=4"`
+	SessionID   string `json:"-"`
+}
+
+// SeatHold is a seat lock owned by a session, kept in redis until it expires,
+// is released or is converted into a reservation.
+type SeatHold struct {
+	ID         string    `json:"id"`
+	SessionID  string    `json:"session_id"`
+	ScheduleID int64     `json:"schedule_id"`
+	WagonID    int64     `json:"wagon_id"`
+	SeatID     int64     `json:"seat_id"`
+	FromStop   int32     `json:"from_stop"`
+	ToStop     int32     `json:"to_stop"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type HoldResponse struct {
+	HoldID     string    `json:"hold_id"`
+	ScheduleID int64     `json:"schedule_id"`
+	WagonID    int64     `json:"wagon_id"`
+	SeatID     int64     `json:"seat_id"`
+	FromStop   int32     `json:"from_stop"`
+	ToStop     int32     `json:"to_stop"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+const (
+	// IdempotencyTTL is how long the response to an idempotent request is replayed
+	IdempotencyTTL = 24 * time.Hour
+	// IdempotencyLockTTL bounds how long a request keeps its key claimed while it is processed,
+	// so a crashed request does not block its retries forever
+	IdempotencyLockTTL = time.Minute
+)
+
+// IdempotencyRecord is the state of a request sent with an Idempotency-Key header.
+// It is claimed while the request is processed and holds the response once it completed.
+type IdempotencyRecord struct {
+	Completed   bool   `json:"completed"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+// JourneyConfig tunes the journey planner, read from the journey section of config.json.
+type JourneyConfig struct {
+	// MinConnectionMinutes is the time needed to change trains at any station
+	MinConnectionMinutes int `mapstructure:"min_connection_minutes"`
+	// ConnectionMinutes overrides the connection time per station code
+	ConnectionMinutes map[string]int `mapstructure:"connection_minutes"`
+	// MaxTransfers caps how many times a journey changes trains
+	MaxTransfers int `mapstructure:"max_transfers"`
+	// MaxDurationHours caps the time between the first departure and the final arrival
+	MaxDurationHours int `mapstructure:"max_duration_hours"`
+	// MaxResults caps how many journeys a search returns
+	MaxResults int `mapstructure:"max_results"`
+}
+
+// DefaultJourneyConfig applies to every setting missing from config.json
+var DefaultJourneyConfig = JourneyConfig{
+	MinConnectionMinutes: 15,
+	MaxTransfers:         2,
+	MaxDurationHours:     24,
+	MaxResults:           10,
+}
+
+type JourneySearchRequest struct {
+	FromStation   string `query:"from" validate:"required,max=4"`
+	ToStation     string `query:"to" validate:"required,max=4,nefield=FromStation"`
+	DepartureDate string `query:"date" validate:"required"`
+	// ClassType prices the journeys in one class, without it every ride is priced in the cheapest class
+	ClassType string `query:"class" validate:"omitempty,oneof=premium economy luxury"`
+}
+
+// JourneyLeg is one train ride of a journey, booked by its schedule and stations. The price
+// is the total of a ticket for an adult, like a quote without discount.
+type JourneyLeg struct {
+	ScheduleID    int64     `json:"schedule_id"`
+	TrainName     string    `json:"train_name"`
+	FromStation   string    `json:"from_station"`
+	ToStation     string    `json:"to_station"`
+	DepartureDate time.Time `json:"departure_date"`
+	ArrivalDate   time.Time `json:"arrival_date"`
+	Price         int64     `json:"price"`
+}
+
+type JourneyResponse struct {
+	Legs            []JourneyLeg `json:"legs"`
+	Transfers       int          `json:"transfers"`
+	DurationMinutes int64        `json:"duration_minutes"`
+	TotalPrice      int64        `json:"total_price"`
+}
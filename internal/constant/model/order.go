@@ -0,0 +1,63 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type OrderItemRequest struct {
+	PassengerID uuid.UUID `json:"passenger_id" validate:"required"`
+	WagonID     int64     `json:"wagon_id" validate:"required"`
+	SeatID      int64     `json:"seat_id" validate:"required"`
+}
+
+type OrderRequest struct {
+	ScheduleID  int64              `json:"schedule_id" validate:"required"`
+	DiscountID  uuid.UUID          `json:"discount_id"`
+	FromStation string             `json:"from_station" validate:"max=4"`
+	ToStation   string             `json:"to_station" validate:"max=4"`
+	Items       []OrderItemRequest `json:"items" validate:"required,min=1,max=8,dive"`
+	SessionID   string             `json:"-"`
+}
+
+type AutoOrderRequest struct {
+	ScheduleID   int64       `json:"schedule_id" validate:"required"`
+	ClassType    string      `json:"class_type" validate:"required,oneof=premium economy luxury"`
+	DiscountID   uuid.UUID   `json:"discount_id"`
+	FromStation  string      `json:"from_station" validate:"max=4"`
+	ToStation    string      `json:"to_station" validate:"max=4"`
+	PassengerIDs []uuid.UUID `json:"passenger_ids" validate:"required,min=1,max=8,dive,required"`
+	SessionID    string      `json:"-"`
+}
+
+type OrderResponse struct {
+	ID           uuid.UUID        `json:"id"`
+	BookingCode  string           `json:"booking_code"`
+	TotalPrice   int64            `json:"total_price"`
+	ExpiresAt    pgtype.Timestamp `json:"expires_at"`
+	Reservations []Reservation    `json:"reservations"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+}
+
+type OrderDetailResponse struct {
+	ID           uuid.UUID                  `json:"id"`
+	BookingCode  string                     `json:"booking_code"`
+	TotalPrice   int64                      `json:"total_price"`
+	ExpiresAt    pgtype.Timestamp           `json:"expires_at"`
+	Reservations []ListReservationsResponse `json:"reservations"`
+	CreatedAt    pgtype.Timestamp           `json:"created_at"`
+}
+
+type ItineraryLegRequest struct {
+	ScheduleID  int64              `json:"schedule_id" validate:"required"`
+	FromStation string             `json:"from_station" validate:"max=4"`
+	ToStation   string             `json:"to_station" validate:"max=4"`
+	Items       []OrderItemRequest `json:"items" validate:"required,min=1,max=8,dive"`
+}
+
+// ItineraryRequest books a round trip or multi-city journey in one order, legs are travelled in order
+type ItineraryRequest struct {
+	DiscountID uuid.UUID             `json:"discount_id"`
+	Legs       []ItineraryLegRequest `json:"legs" validate:"required,min=1,max=6,dive"`
+	SessionID  string                `json:"-"`
+}
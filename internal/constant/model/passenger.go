@@ -6,12 +6,13 @@ import (
 )
 
 type Passenger struct {
-	ID        uuid.UUID        `json:"id"`
-	Name      string           `json:"name"`
-	IDNumber  string           `json:"id_number"`
-	UserID    pgtype.UUID      `json:"user_id"`
-	CreatedAt pgtype.Timestamp `json:"created_at"`
-	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+	ID            uuid.UUID        `json:"id"`
+	Name          string           `json:"name"`
+	IDNumber      string           `json:"id_number"`
+	UserID        pgtype.UUID      `json:"user_id"`
+	PassengerType string           `json:"passenger_type"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
 }
 
 type PassengerRequest struct {
@@ -19,4 +20,7 @@ type PassengerRequest struct {
 	Name     string    `json:"name" validate:"required"`
 	IDNumber string    `json:"id_number" validate:"required,max=36"`
 	UserID   uuid.UUID `json:"user_id" validate:"omitempty"`
+	// PassengerType is only set by admins and general affairs once they checked the
+	// passenger's documents, other passengers travel as model.DefaultPassengerType
+	PassengerType string `json:"passenger_type" validate:"max=20"`
 }
@@ -6,6 +6,13 @@ type PaymentRequest struct {
 	ReservationID uuid.UUID `json:"reservation_id" validate:"required"`
 	PaymentMethod string    `json:"payment_method" validate:"required"`
 	Amount        int64     `json:"amount"`
+	SessionID     string    `json:"-"`
+}
+
+type OrderPaymentRequest struct {
+	PaymentMethod string `json:"payment_method" validate:"required"`
+	Amount        int64  `json:"amount" validate:"required,gt=0"`
+	SessionID     string `json:"-"`
 }
 
 type PaymentResponse struct {
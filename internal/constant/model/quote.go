@@ -0,0 +1,65 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultPassengerType is the passenger type of every passenger whose type was not verified
+const DefaultPassengerType = "adult"
+
+// QuoteTTL is how long the price of a quote is guaranteed to a booking
+const QuoteTTL = 15 * time.Minute
+
+// PricingConfig holds the fare rules, read from the pricing section of config.json. Every
+// missing setting costs nothing, so without the section a ticket costs its base fare less
+// the discount.
+type PricingConfig struct {
+	// ClassSupplementPercent raises the base fare per wagon class
+	ClassSupplementPercent map[string]int `mapstructure:"class_supplement_percent"`
+	// PassengerTypes adjusts the fare per passenger type, negative values are reductions
+	PassengerTypes map[string]int `mapstructure:"passenger_types"`
+	// BookingFee is charged once per ticket
+	BookingFee int64 `mapstructure:"booking_fee"`
+	// TaxPercent is charged on the discounted fare and the booking fee
+	TaxPercent int `mapstructure:"tax_percent"`
+}
+
+// FareItem is one line of a fare breakdown, reductions are negative
+type FareItem struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Amount      int64  `json:"amount"`
+}
+
+type Fare struct {
+	Items []FareItem `json:"items"`
+	Total int64      `json:"total"`
+}
+
+type QuoteRequest struct {
+	ScheduleID    int64     `json:"schedule_id" validate:"required"`
+	ClassType     string    `json:"class_type" validate:"required,oneof=premium economy luxury"`
+	FromStation   string    `json:"from_station" validate:"max=4"`
+	ToStation     string    `json:"to_station" validate:"max=4"`
+	PassengerType string    `json:"passenger_type" validate:"max=20"`
+	DiscountID    uuid.UUID `json:"discount_id"`
+	SessionID     string    `json:"-"`
+}
+
+// Quote is the itemized price of one ticket, a reservation made with its id before it
+// expires is charged the quoted total
+type Quote struct {
+	ID            string     `json:"id"`
+	ScheduleID    int64      `json:"schedule_id"`
+	ClassType     string     `json:"class_type"`
+	FromStop      int32      `json:"from_stop"`
+	ToStop        int32      `json:"to_stop"`
+	PassengerType string     `json:"passenger_type"`
+	DiscountID    uuid.UUID  `json:"discount_id"`
+	Items         []FareItem `json:"items"`
+	Total         int64      `json:"total"`
+	Currency      string     `json:"currency"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// RefundFee is one step of the cancellation fee schedule: refunds requested at least
+// MinHoursBeforeDeparture hours before departure keep FeePercent of the paid amount.
+type RefundFee struct {
+	MinHoursBeforeDeparture int `mapstructure:"min_hours_before_departure" json:"min_hours_before_departure"`
+	FeePercent              int `mapstructure:"fee_percent" json:"fee_percent"`
+}
+
+// DefaultRefundFees applies when config.json has no refund.fees: 25% more than 48 hours
+// before departure, 50% after that and nothing once the train has left.
+var DefaultRefundFees = []RefundFee{
+	{MinHoursBeforeDeparture: 48, FeePercent: 25},
+	{MinHoursBeforeDeparture: 0, FeePercent: 50},
+}
+
+type RefundRequest struct {
+	ReservationID uuid.UUID `json:"reservation_id" validate:"required"`
+	Reason        string    `json:"reason" validate:"max=255"`
+}
+
+type RefundResponse struct {
+	ID            uuid.UUID        `json:"id"`
+	ReservationID uuid.UUID        `json:"reservation_id"`
+	PaymentID     pgtype.UUID      `json:"payment_id"`
+	PaidAmount    int64            `json:"paid_amount"`
+	FeePercent    int32            `json:"fee_percent"`
+	Fee           int64            `json:"fee"`
+	Amount        int64            `json:"amount"`
+	Reason        *string          `json:"reason"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+}
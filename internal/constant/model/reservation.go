@@ -14,6 +14,11 @@ type ReservationRequest struct {
 	WagonID     int64       `json:"wagon_id" validate:"required,max=50"`
 	Seat_id     int64       `json:"seat_id" validate:"required,max=50"`
 	DiscountID  uuid.UUID   `json:"discount_id" validate:"max=50"`
+	FromStation string      `json:"from_station" validate:"max=4"`
+	ToStation   string      `json:"to_station" validate:"max=4"`
+	HoldID      string      `json:"hold_id"`
+	QuoteID     string      `json:"quote_id" validate:"omitempty,uuid"`
+	SessionID   string      `json:"-"`
 }
 
 type ReservationResponse struct {
@@ -26,10 +31,14 @@ type ReservationResponse struct {
 
 type Reservation struct {
 	ID                uuid.UUID        `json:"id"`
+	OrderID           uuid.UUID        `json:"order_id"`
+	BookingCode       string           `json:"booking_code"`
 	PassengerID       uuid.UUID        `json:"passenger_id"`
 	ScheduleID        int64            `json:"schedule_id"`
 	WagonID           int64            `json:"wagon_id"`
 	SeatID            int64            `json:"seat_id"`
+	FromStop          int32            `json:"from_stop"`
+	ToStop            int32            `json:"to_stop"`
 	BookingDate       pgtype.Timestamp `json:"booking_date"`
 	DiscountID        pgtype.UUID      `json:"discount_id"`
 	Price             *int64           `json:"price"`
@@ -40,24 +49,83 @@ type Reservation struct {
 }
 
 type ListReservationsResponse struct {
-	ReservationID      uuid.UUID        `json:"reservation_id"`
-	PassengerName      *string          `json:"passenger_name"`
-	PassengerIDNumber  *string          `json:"passenger_id_number"`
-	UserName           *string          `json:"user_name"`
-	UserEmail          *string          `json:"user_email"`
-	DepartureDate      pgtype.Timestamp `json:"departure_date"`
-	ArrivalDate        pgtype.Timestamp `json:"arrival_date"`
-	TicketPrice        *int64           `json:"ticket_price"`
-	TrainName          *string          `json:"train_name"`
-	ClassType          string           `json:"class_type"`
-	SeatNumber         string           `json:"seat_number"`
-	BookingDate        pgtype.Timestamp `json:"booking_date"`
-	ReservationStatus  string           `json:"reservation_status"`
-	SourceStation      *string          `json:"source_station"`
-	DestinationStation *string          `json:"destination_station"`
-	DiscountCode       *string          `json:"discount_code"`
-	DiscountPercent    *int32           `json:"discount_percent"`
-	PaymentAmount      *int64           `json:"payment_amount"`
-	PaymentMethod      *string          `json:"payment_method"`
-	PaymentStatus      *string          `json:"payment_status"`
+	ReservationID      uuid.UUID                 `json:"reservation_id"`
+	BookingCode        string                    `json:"booking_code"`
+	PassengerName      *string                   `json:"passenger_name"`
+	PassengerIDNumber  *string                   `json:"passenger_id_number"`
+	UserName           *string                   `json:"user_name"`
+	UserEmail          *string                   `json:"user_email"`
+	DepartureDate      pgtype.Timestamp          `json:"departure_date"`
+	ArrivalDate        pgtype.Timestamp          `json:"arrival_date"`
+	TicketPrice        *int64                    `json:"ticket_price"`
+	TrainName          *string                   `json:"train_name"`
+	ClassType          string                    `json:"class_type"`
+	SeatNumber         string                    `json:"seat_number"`
+	BookingDate        pgtype.Timestamp          `json:"booking_date"`
+	ReservationStatus  string                    `json:"reservation_status"`
+	SourceStation      *string                   `json:"source_station"`
+	DestinationStation *string                   `json:"destination_station"`
+	DiscountCode       *string                   `json:"discount_code"`
+	DiscountPercent    *int32                    `json:"discount_percent"`
+	PaymentAmount      *int64                    `json:"payment_amount"`
+	PaymentMethod      *string                   `json:"payment_method"`
+	PaymentStatus      *string                   `json:"payment_status"`
+	StatusHistory      []ReservationStatusChange `json:"status_history,omitempty"`
+}
+
+// ReservationStatusChange is one entry of a reservation's status history, FromStatus is empty on creation
+type ReservationStatusChange struct {
+	FromStatus *string   `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason"`
+	Actor      string    `json:"actor"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// MyReservationsRequest filters the reservations of the session owner. Trip narrows them to
+// upcoming, past or cancelled trips, From and To bound the departure date (both inclusive).
+type MyReservationsRequest struct {
+	Trip   string `query:"trip" validate:"omitempty,oneof=upcoming past cancelled"`
+	Status string `query:"status" validate:"omitempty,oneof=pending success cancelled refunded"`
+	From   string `query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To     string `query:"to" validate:"omitempty,datetime=2006-01-02"`
+	Page   int    `query:"page" validate:"omitempty,min=1"`
+	Size   int    `query:"size" validate:"omitempty,min=1,max=100"`
+}
+
+// ReservationSearchRequest filters and sorts every reservation for admins. Passenger matches
+// part of the passenger name or the whole ID number, Email part of the user email.
+type ReservationSearchRequest struct {
+	From         string `query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To           string `query:"to" validate:"omitempty,datetime=2006-01-02"`
+	RouteID      int64  `query:"route_id" validate:"omitempty,min=1"`
+	TrainID      int64  `query:"train_id" validate:"omitempty,min=1"`
+	Status       string `query:"status" validate:"omitempty,oneof=pending success cancelled refunded"`
+	Passenger    string `query:"passenger" validate:"max=100"`
+	Email        string `query:"email" validate:"max=100"`
+	DiscountCode string `query:"discount_code" validate:"max=50"`
+	SortBy       string `query:"sort_by" validate:"omitempty,oneof=booking_date departure_date arrival_date ticket_price booking_code passenger_name user_email train_name class_type reservation_status source_station destination_station discount_code"`
+	Order        string `query:"order" validate:"omitempty,oneof=asc desc"`
+	Page         int    `query:"page" validate:"omitempty,min=1"`
+	Size         int    `query:"size" validate:"omitempty,min=1,max=100"`
+}
+
+type ExchangeRequest struct {
+	ReservationID uuid.UUID `json:"reservation_id" validate:"required"`
+	ScheduleID    int64     `json:"schedule_id" validate:"required"`
+	WagonID       int64     `json:"wagon_id" validate:"required"`
+	SeatID        int64     `json:"seat_id" validate:"required"`
+	FromStation   string    `json:"from_station" validate:"max=4"`
+	ToStation     string    `json:"to_station" validate:"max=4"`
+	PaymentMethod string    `json:"payment_method"`
+	SessionID     string    `json:"-"`
+}
+
+type ExchangeResponse struct {
+	OriginalReservationID uuid.UUID   `json:"original_reservation_id"`
+	Reservation           Reservation `json:"reservation"`
+	FareDifference        int64       `json:"fare_difference"`
+	AmountCollected       int64       `json:"amount_collected"`
+	AmountRefunded        int64       `json:"amount_refunded"`
+	AmountDue             int64       `json:"amount_due"`
 }
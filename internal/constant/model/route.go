@@ -61,3 +61,18 @@ type RouteRequest struct {
 	DestinationStation string `json:"destination_station" validate:"required,max=4"`
 	TravelTime         int32  `json:"travel_time"`
 }
+
+type RouteStop struct {
+	StopOrder        int32  `json:"stop_order"`
+	StationCode      string `json:"station_code"`
+	MinutesFromStart int32  `json:"minutes_from_start"`
+}
+
+type RouteStopRequest struct {
+	StationCode      string `json:"station_code" validate:"required,max=4"`
+	MinutesFromStart int32  `json:"minutes_from_start" validate:"min=0"`
+}
+
+type RouteStopsRequest struct {
+	Stops []RouteStopRequest `json:"stops" validate:"required,min=2,dive"`
+}
@@ -5,23 +5,21 @@ import (
 )
 
 type ScheduleRequest struct {
-	TrainID        int64            `json:"train_id" validate:"required"`
-	DepartureDate  pgtype.Timestamp `json:"departure_time" validate:"required"`
-	AvailableSeats int32            `json:"available_seats" validate:"required"`
-	Price          int64            `json:"price" validate:"required"`
-	RouteID        int64            `json:"route_id" validate:"required"`
+	TrainID       int64            `json:"train_id" validate:"required"`
+	DepartureDate pgtype.Timestamp `json:"departure_time" validate:"required"`
+	Price         int64            `json:"price" validate:"required"`
+	RouteID       int64            `json:"route_id" validate:"required"`
 }
 
 type Schedule struct {
-	ID             int64            `json:"id"`
-	TrainID        int64            `json:"train_id"`
-	RouteID        int64            `json:"route_id"`
-	DepartureDate  pgtype.Timestamp `json:"departure_date"`
-	ArrivalDate    pgtype.Timestamp `json:"arrival_date"`
-	Price          int64            `json:"price"`
-	AvailableSeats int32            `json:"available_seats"`
-	CreatedAt      pgtype.Timestamp `json:"created_at"`
-	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+	ID            int64            `json:"id"`
+	TrainID       int64            `json:"train_id"`
+	RouteID       int64            `json:"route_id"`
+	DepartureDate pgtype.Timestamp `json:"departure_date"`
+	ArrivalDate   pgtype.Timestamp `json:"arrival_date"`
+	Price         int64            `json:"price"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
 }
 
 type SearchScheduleRequest struct {
@@ -30,13 +28,71 @@ type SearchScheduleRequest struct {
 	DepartureDate      string  `json:"departure_date" validate:"required"`
 }
 
+// SearchScheduleResponse is a schedule found by a search. Prices are what an adult pays for the
+// whole route without a discount, the price is the lowest over the classes with seats left.
 type SearchScheduleResponse struct {
-	ScheduleID         int64            `json:"schedule_id"`
-	TrainName          string           `json:"train_name"`
-	SourceStation      string           `json:"source_station"`
-	DestinationStation string           `json:"destination_station"`
-	DepartureDate      pgtype.Timestamp `json:"departure_date"`
-	ArrivalDate        pgtype.Timestamp `json:"arrival_date"`
-	AvailableSeats     int32            `json:"available_seats"`
-	Price              int64            `json:"price"`
+	ScheduleID         int64               `json:"schedule_id"`
+	TrainName          string              `json:"train_name"`
+	SourceStation      string              `json:"source_station"`
+	DestinationStation string              `json:"destination_station"`
+	DepartureDate      pgtype.Timestamp    `json:"departure_date"`
+	ArrivalDate        pgtype.Timestamp    `json:"arrival_date"`
+	AvailableSeats     int64               `json:"available_seats"`
+	Classes            []ClassAvailability `json:"classes"`
+	Price              int64               `json:"price"`
+}
+
+// ItinerarySearchRequest searches every leg of a journey at once,
+// a round trip is two legs with the stations swapped
+type ItinerarySearchRequest struct {
+	Legs []SearchScheduleRequest `json:"legs" validate:"required,min=1,max=6,dive"`
+}
+
+type ItineraryLegOptions struct {
+	Leg       int                      `json:"leg"`
+	Schedules []SearchScheduleResponse `json:"schedules"`
+}
+
+// ItinerarySearchResponse lists the schedules of every leg. The cheapest total is the lowest
+// combined price for one passenger over schedules with seats left that connect in time, nil when none do.
+type ItinerarySearchResponse struct {
+	Legs                []ItineraryLegOptions `json:"legs"`
+	CheapestTotal       *int64                `json:"cheapest_total"`
+	CheapestScheduleIDs []int64               `json:"cheapest_schedule_ids"`
+}
+
+type ClassAvailability struct {
+	ClassType      string `json:"class_type"`
+	TotalSeats     int64  `json:"total_seats"`
+	AvailableSeats int64  `json:"available_seats"`
+	Price          int64  `json:"price"`
+}
+
+// seat states reported by the seat map
+const (
+	SeatStateFree    = "free"
+	SeatStateHeld    = "held"
+	SeatStateBooked  = "booked"
+	SeatStateBlocked = "blocked"
+)
+
+type SeatMapSeat struct {
+	SeatID     int64  `json:"seat_id"`
+	SeatNumber int32  `json:"seat_number"`
+	SeatRow    string `json:"seat_row"`
+	State      string `json:"state"`
+}
+
+type SeatMapWagon struct {
+	WagonID     int64         `json:"wagon_id"`
+	WagonNumber int32         `json:"wagon_number"`
+	ClassType   string        `json:"class_type"`
+	Seats       []SeatMapSeat `json:"seats"`
+}
+
+type SeatMapResponse struct {
+	ScheduleID int64          `json:"schedule_id"`
+	FromStop   int32          `json:"from_stop"`
+	ToStop     int32          `json:"to_stop"`
+	Wagons     []SeatMapWagon `json:"wagons"`
 }
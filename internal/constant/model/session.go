@@ -15,4 +15,23 @@ type Session struct {
 	ClientIP     string     `json:"client_ip"`
 	IsBlocked    bool       `json:"is_blocked"`
 	ExpiresAt    time.Time  `json:"expires_at"`
+	// GuestSessionID is the guest session merged into this one on login, or into the account on
+	// registering, the seat holds, seat locks, quotes and booking access of the guest carry over to this session
+	GuestSessionID string `json:"guest_session_id,omitempty"`
+}
+
+// ActorSystem is recorded as the actor of changes made by background jobs
+const ActorSystem = "system"
+
+// Actor describes who acts through the session, "<role>:<user id>" for signed in users and "guest" otherwise
+func (s *Session) Actor() string {
+	if s.UserID == nil {
+		return "guest"
+	}
+	return s.Role + ":" + s.UserID.String()
+}
+
+// Privileged reports whether the session may act on the reservations and passengers of every customer
+func (s *Session) Privileged() bool {
+	return s.Role == "admin" || s.Role == "general affairs"
 }
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TicketStation is a station printed on a ticket
+type TicketStation struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// Ticket is the e-ticket of a paid reservation, for the stations the passenger travels between
+type Ticket struct {
+	ReservationID     uuid.UUID     `json:"reservation_id"`
+	BookingCode       string        `json:"booking_code"`
+	PassengerName     string        `json:"passenger_name"`
+	PassengerIDNumber string        `json:"passenger_id_number"`
+	TrainName         string        `json:"train_name"`
+	ClassType         string        `json:"class_type"`
+	SeatNumber        string        `json:"seat_number"`
+	From              TicketStation `json:"from"`
+	To                TicketStation `json:"to"`
+	DepartureDate     time.Time     `json:"departure_date"`
+	ArrivalDate       time.Time     `json:"arrival_date"`
+	Price             int64         `json:"price"`
+	// QRCode is the content of the QR code printed on the ticket, the signed ticket token
+	QRCode string `json:"qr_code,omitempty"`
+}
+
+type TicketTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// TicketPublicKeyResponse is the key that verifies ticket tokens offline
+type TicketPublicKeyResponse struct {
+	Algorithm string `json:"algorithm"`
+	Format    string `json:"format"`
+	PublicKey string `json:"public_key"`
+}
+
+// BoardingRequest is a ticket token scanned by a conductor on board of a schedule
+type BoardingRequest struct {
+	Token      string `json:"token" validate:"required"`
+	ScheduleID int64  `json:"schedule_id" validate:"required"`
+}
+
+type BoardingResponse struct {
+	Ticket    Ticket    `json:"ticket"`
+	BoardedAt time.Time `json:"boarded_at"`
+}
+
+type CheckInResponse struct {
+	Ticket      Ticket    `json:"ticket"`
+	CheckedInAt time.Time `json:"checked_in_at"`
+}
+
+// NoShowReportRequest filters the no-show report by departure date and route
+type NoShowReportRequest struct {
+	From    string `query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To      string `query:"to" validate:"omitempty,datetime=2006-01-02"`
+	RouteID int64  `query:"route_id" validate:"omitempty,min=1"`
+}
+
+// NoShowReportResponse counts the travelled tickets of a route, a ticket counts once its
+// passenger boarded or was marked as a no-show
+type NoShowReportResponse struct {
+	RouteID            int64   `json:"route_id"`
+	SourceStation      string  `json:"source_station"`
+	DestinationStation string  `json:"destination_station"`
+	Tickets            int64   `json:"tickets"`
+	Boarded            int64   `json:"boarded"`
+	NoShows            int64   `json:"no_shows"`
+	NoShowRate         float64 `json:"no_show_rate"`
+	CheckedIn          int64   `json:"checked_in"`
+	CheckedInNoShows   int64   `json:"checked_in_no_shows"`
+}
@@ -9,6 +9,8 @@ import (
 type LoginUserRequest struct {
 	Email    string `json:"email" validate:"required,max=100"`
 	Password string `json:"password" validate:"required,max=100"`
+	// GuestSessionID is the guest session the client browsed with, its bookings move to the user
+	GuestSessionID string `json:"-"`
 }
 
 type RegisterUserRequest struct {
@@ -16,6 +18,8 @@ type RegisterUserRequest struct {
 	Email       string `json:"email" validate:"required,email,max=50"`
 	Password    string `json:"password" validate:"required,max=100"`
 	PhoneNumber string `json:"phone_number" validate:"required,max=50"`
+	// GuestSessionID is merged into the new account like on login
+	GuestSessionID string `json:"-"`
 }
 
 type UserResponse struct {
@@ -32,5 +36,5 @@ type RegisterAdminRequest struct {
 	Email       string `json:"email" validate:"required,max=50"`
 	Password    string `json:"password" validate:"required,max=100"`
 	PhoneNumber string `json:"phone_number" validate:"required,max=50"`
-	Role        string `json:"role" validate:"required,oneof=admin general affair"`
+	Role        string `json:"role" validate:"required,oneof=admin 'general affairs' conductor"`
 }
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type WaitlistRequest struct {
+	PassengerID uuid.UUID `json:"passenger_id" validate:"required"`
+	ScheduleID  int64     `json:"schedule_id" validate:"required"`
+	ClassType   string    `json:"class_type" validate:"required,oneof=premium economy luxury"`
+	FromStation string    `json:"from_station" validate:"max=4"`
+	ToStation   string    `json:"to_station" validate:"max=4"`
+}
+
+type WaitlistResponse struct {
+	ID            uuid.UUID        `json:"id"`
+	PassengerID   uuid.UUID        `json:"passenger_id"`
+	ScheduleID    int64            `json:"schedule_id"`
+	ClassType     string           `json:"class_type"`
+	FromStop      int32            `json:"from_stop"`
+	ToStop        int32            `json:"to_stop"`
+	Status        string           `json:"status"`
+	Position      int64            `json:"position,omitempty"`
+	ReservationID pgtype.UUID      `json:"reservation_id"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+}
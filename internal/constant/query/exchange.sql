@@ -0,0 +1,7 @@
+-- name: CreateReservationExchange :one
+INSERT INTO reservation_exchanges (
+   original_reservation_id, new_reservation_id, fare_difference
+) VALUES (
+    $1, $2, $3
+)
+RETURNING *;
@@ -0,0 +1,81 @@
+-- name: CreateOrder :one
+-- a booking code already in use inserts no row, the caller draws another code
+INSERT INTO orders (
+   booking_code, total_price, expires_at
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (booking_code) DO NOTHING
+RETURNING *;
+
+-- name: ExpireSettledOrder :exec
+-- an order left without pending reservations expires now, nothing of it awaits payment anymore
+UPDATE orders
+SET expires_at = LEAST(expires_at, NOW()), updated_at = NOW()
+WHERE id = $1 AND NOT EXISTS (
+  SELECT 1 FROM reservations
+  WHERE order_id = $1 AND reservation_status = 'pending'
+);
+
+-- name: GetOrder :one
+SELECT * FROM orders
+WHERE id = $1 LIMIT 1;
+
+-- name: GetOrderByCode :one
+SELECT * FROM orders
+WHERE booking_code = $1 LIMIT 1;
+
+-- name: ListOrderReservations :many
+SELECT * FROM reservations
+WHERE order_id = $1
+ORDER BY created_at;
+
+-- name: ListFullReservationsByOrder :many
+SELECT 
+r.id AS reservation_id,
+o.booking_code,
+p.name AS passenger_name,
+p.id_number AS passenger_id_number,
+u.name AS user_name,
+u.email AS user_email,
+s.departure_date,
+s.arrival_date,
+r.price AS ticket_price,
+t.name AS train_name,
+w.class_type,
+w.wagon_number,
+st.seat_number,
+st.seat_row,
+r.booking_date,
+r.reservation_status,
+rt.source_station,
+rt.destination_station,
+d.code AS discount_code,
+d.discount_percent,
+py.amount AS payment_amount,
+py.payment_method,
+py.payment_status 
+FROM reservations r
+JOIN orders o ON r.order_id = o.id
+LEFT JOIN passengers p ON r.passenger_id = p.id
+LEFT JOIN users u ON p.user_id = u.id
+LEFT JOIN schedules s ON r.schedule_id = s.id
+LEFT JOIN seats st ON r.seat_id = st.id
+LEFT JOIN wagons w ON r.wagon_id = w.id
+LEFT JOIN trains t ON s.train_id = t.id
+LEFT JOIN routes rt ON s.route_id = rt.id
+LEFT JOIN discount_codes d ON r.discount_id = d.id
+LEFT JOIN LATERAL (
+  SELECT amount, payment_method, payment_status FROM payments
+  WHERE reservation_id = r.id OR (reservation_id IS NULL AND order_id = r.order_id)
+  ORDER BY created_at DESC
+  LIMIT 1
+) py ON true
+WHERE r.order_id = $1
+ORDER BY r.created_at;
+
+-- name: UpdateOrderContact :one
+UPDATE orders
+SET contact_email = $2, contact_phone = $3, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
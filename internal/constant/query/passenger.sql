@@ -7,9 +7,9 @@ SELECT * FROM passengers
 ORDER BY name;
 
 -- name: CreatePassenger :one
-INSERT INTO passengers (id, name, id_number, user_id) 
+INSERT INTO passengers (id, name, id_number, user_id, session_id, passenger_type) 
 VALUES (
-  $1, $2, $3, $4
+  $1, $2, $3, $4, $5, $6
 )
 RETURNING *;
 
@@ -17,7 +17,8 @@ RETURNING *;
 UPDATE passengers
   set name = $2,
   id_number = $3,
-  user_id = $4
+  user_id = $4,
+  passenger_type = $5
 WHERE id = $1;
 
 
@@ -26,5 +27,20 @@ DELETE FROM passengers
 WHERE id = $1;
 
 -- name: GetPassengerByUser :one
+-- the oldest passenger of the user is the default one, a user owns several after creating
+-- more or merging guest bookings
 SELECT * FROM passengers
-WHERE user_id = $1;
+WHERE user_id = $1
+ORDER BY created_at, id
+LIMIT 1;
+
+-- name: ListPassengersByUser :many
+SELECT * FROM passengers
+WHERE user_id = $1
+ORDER BY created_at, id;
+
+-- name: MergeGuestPassengers :many
+UPDATE passengers
+SET user_id = $1, session_id = NULL, updated_at = NOW()
+WHERE session_id = $2 AND user_id IS NULL
+RETURNING id;
@@ -6,11 +6,18 @@ WHERE id = $1 LIMIT 1;
 SELECT * FROM payments
 ORDER BY id;
 
+-- name: GetReservationPayment :one
+SELECT py.* FROM payments py
+JOIN reservations r ON py.reservation_id = r.id OR (py.reservation_id IS NULL AND py.order_id = r.order_id)
+WHERE r.id = $1 AND py.payment_status = 'success'
+ORDER BY py.payment_date DESC
+LIMIT 1;
+
 -- name: CreatePayment :exec
 INSERT INTO payments (
-    reservation_id, payment_method, amount, transaction_id, payment_date, gateway_response, payment_status
+    reservation_id, payment_method, amount, transaction_id, payment_date, gateway_response, payment_status, order_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, $8
 );
 
 -- name: UpdatePayment :exec
@@ -36,7 +43,7 @@ WHERE id = $1 AND payment_status = 'pending';
 
 -- name: GetExpiredPayments :many
 SELECT reservation_id FROM payments
-WHERE payment_status = 'pending' AND creaate_at < NOW() - INTERVAL '15 minutes';
+WHERE payment_status = 'pending' AND created_at < NOW() - INTERVAL '15 minutes';
 
 -- name: DeletePayment :exec
 DELETE FROM payments
@@ -0,0 +1,11 @@
+-- name: CreateRefund :one
+INSERT INTO refunds (
+   reservation_id, payment_id, paid_amount, fee_percent, fee, amount, reason
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: GetRefundByReservation :one
+SELECT * FROM refunds
+WHERE reservation_id = $1 LIMIT 1;
@@ -5,6 +5,7 @@ WHERE id = $1 LIMIT 1;
 -- name: ListReservations :many
 SELECT 
 r.id AS reservation_id,
+o.booking_code,
 p.name AS passenger_name,
 p.id_number AS passenger_id_number,
 u.name AS user_name,
@@ -27,6 +28,7 @@ py.amount AS payment_amount,
 py.payment_method,
 py.payment_status
 FROM reservations r
+JOIN orders o ON r.order_id = o.id
 LEFT JOIN passengers p ON r.passenger_id = p.id
 LEFT JOIN users u ON p.user_id = u.id
 LEFT JOIN schedules s ON r.schedule_id = s.id
@@ -35,19 +37,135 @@ LEFT JOIN wagons w ON r.wagon_id = w.id
 LEFT JOIN trains t ON s.train_id = t.id
 LEFT JOIN routes rt ON s.route_id = rt.id
 LEFT JOIN discount_codes d ON r.discount_id = d.id
-LEFT JOIN payments py ON r.id = py.reservation_id
-ORDER BY booking_date DESC
-LIMIT $1
-OFFSET $2;
+LEFT JOIN LATERAL (
+  SELECT amount, payment_method, payment_status FROM payments
+  WHERE reservation_id = r.id OR (reservation_id IS NULL AND order_id = r.order_id)
+  ORDER BY created_at DESC
+  LIMIT 1
+) py ON true
+WHERE (sqlc.narg('departure_from')::timestamp IS NULL OR s.departure_date >= sqlc.narg('departure_from')::timestamp)
+  AND (sqlc.narg('departure_to')::timestamp IS NULL OR s.departure_date < sqlc.narg('departure_to')::timestamp)
+  AND (sqlc.narg('route_id')::bigint IS NULL OR s.route_id = sqlc.narg('route_id')::bigint)
+  AND (sqlc.narg('train_id')::bigint IS NULL OR s.train_id = sqlc.narg('train_id')::bigint)
+  AND (sqlc.narg('status')::text IS NULL OR r.reservation_status::text = sqlc.narg('status')::text)
+  AND (sqlc.narg('passenger')::text IS NULL OR p.name ILIKE '%' || regexp_replace(sqlc.narg('passenger')::text, '([\\%_])', '\\\1', 'g') || '%' OR p.id_number = sqlc.narg('passenger')::text)
+  AND (sqlc.narg('email')::text IS NULL OR u.email ILIKE '%' || regexp_replace(sqlc.narg('email')::text, '([\\%_])', '\\\1', 'g') || '%')
+  AND (sqlc.narg('discount_code')::text IS NULL OR UPPER(d.code) = UPPER(sqlc.narg('discount_code')::text))
+ORDER BY
+  CASE WHEN NOT @sort_desc::bool THEN CASE @sort_by::text
+    WHEN 'booking_date' THEN r.booking_date
+    WHEN 'departure_date' THEN s.departure_date
+    WHEN 'arrival_date' THEN s.arrival_date END END ASC,
+  CASE WHEN @sort_desc::bool THEN CASE @sort_by::text
+    WHEN 'booking_date' THEN r.booking_date
+    WHEN 'departure_date' THEN s.departure_date
+    WHEN 'arrival_date' THEN s.arrival_date END END DESC,
+  CASE WHEN NOT @sort_desc::bool AND @sort_by::text = 'ticket_price' THEN r.price END ASC,
+  CASE WHEN @sort_desc::bool AND @sort_by::text = 'ticket_price' THEN r.price END DESC,
+  CASE WHEN NOT @sort_desc::bool THEN CASE @sort_by::text
+    WHEN 'booking_code' THEN o.booking_code
+    WHEN 'passenger_name' THEN p.name
+    WHEN 'user_email' THEN u.email
+    WHEN 'train_name' THEN t.name
+    WHEN 'class_type' THEN w.class_type::text
+    WHEN 'reservation_status' THEN r.reservation_status::text
+    WHEN 'source_station' THEN rt.source_station
+    WHEN 'destination_station' THEN rt.destination_station
+    WHEN 'discount_code' THEN d.code END END ASC,
+  CASE WHEN @sort_desc::bool THEN CASE @sort_by::text
+    WHEN 'booking_code' THEN o.booking_code
+    WHEN 'passenger_name' THEN p.name
+    WHEN 'user_email' THEN u.email
+    WHEN 'train_name' THEN t.name
+    WHEN 'class_type' THEN w.class_type::text
+    WHEN 'reservation_status' THEN r.reservation_status::text
+    WHEN 'source_station' THEN rt.source_station
+    WHEN 'destination_station' THEN rt.destination_station
+    WHEN 'discount_code' THEN d.code END END DESC,
+  r.booking_date DESC, r.id
+LIMIT @page_limit
+OFFSET @page_offset;
 
 -- name: CountReservations :one
-select count(*) from reservations;
+SELECT COUNT(*) FROM reservations r
+JOIN orders o ON r.order_id = o.id
+LEFT JOIN passengers p ON r.passenger_id = p.id
+LEFT JOIN users u ON p.user_id = u.id
+LEFT JOIN schedules s ON r.schedule_id = s.id
+LEFT JOIN discount_codes d ON r.discount_id = d.id
+WHERE (sqlc.narg('departure_from')::timestamp IS NULL OR s.departure_date >= sqlc.narg('departure_from')::timestamp)
+  AND (sqlc.narg('departure_to')::timestamp IS NULL OR s.departure_date < sqlc.narg('departure_to')::timestamp)
+  AND (sqlc.narg('route_id')::bigint IS NULL OR s.route_id = sqlc.narg('route_id')::bigint)
+  AND (sqlc.narg('train_id')::bigint IS NULL OR s.train_id = sqlc.narg('train_id')::bigint)
+  AND (sqlc.narg('status')::text IS NULL OR r.reservation_status::text = sqlc.narg('status')::text)
+  AND (sqlc.narg('passenger')::text IS NULL OR p.name ILIKE '%' || regexp_replace(sqlc.narg('passenger')::text, '([\\%_])', '\\\1', 'g') || '%' OR p.id_number = sqlc.narg('passenger')::text)
+  AND (sqlc.narg('email')::text IS NULL OR u.email ILIKE '%' || regexp_replace(sqlc.narg('email')::text, '([\\%_])', '\\\1', 'g') || '%')
+  AND (sqlc.narg('discount_code')::text IS NULL OR UPPER(d.code) = UPPER(sqlc.narg('discount_code')::text));
+
+-- name: ListUserReservations :many
+SELECT 
+r.id AS reservation_id,
+o.booking_code,
+p.name AS passenger_name,
+p.id_number AS passenger_id_number,
+u.name AS user_name,
+u.email AS user_email,
+s.departure_date,
+s.arrival_date,
+r.price AS ticket_price,
+t.name AS train_name,
+w.class_type,
+w.wagon_number,
+st.seat_number,
+st.seat_row,
+r.booking_date,
+r.reservation_status,
+rt.source_station,
+rt.destination_station,
+d.code AS discount_code,
+d.discount_percent,
+py.amount AS payment_amount,
+py.payment_method,
+py.payment_status
+FROM reservations r
+JOIN orders o ON r.order_id = o.id
+LEFT JOIN passengers p ON r.passenger_id = p.id
+LEFT JOIN users u ON p.user_id = u.id
+LEFT JOIN schedules s ON r.schedule_id = s.id
+LEFT JOIN seats st ON r.seat_id = st.id
+LEFT JOIN wagons w ON r.wagon_id = w.id
+LEFT JOIN trains t ON s.train_id = t.id
+LEFT JOIN routes rt ON s.route_id = rt.id
+LEFT JOIN discount_codes d ON r.discount_id = d.id
+LEFT JOIN LATERAL (
+  SELECT amount, payment_method, payment_status FROM payments
+  WHERE reservation_id = r.id OR (reservation_id IS NULL AND order_id = r.order_id)
+  ORDER BY created_at DESC
+  LIMIT 1
+) py ON true
+WHERE (p.user_id = sqlc.narg('user_id') OR p.session_id = sqlc.narg('session_id'))
+  AND (cardinality(@statuses::text[]) = 0 OR r.reservation_status::text = ANY(@statuses::text[]))
+  AND (sqlc.narg('departure_from')::timestamp IS NULL OR s.departure_date >= sqlc.narg('departure_from')::timestamp)
+  AND (sqlc.narg('departure_to')::timestamp IS NULL OR s.departure_date < sqlc.narg('departure_to')::timestamp)
+ORDER BY CASE WHEN @ascending::bool THEN s.departure_date END ASC,
+  s.departure_date DESC, r.booking_date DESC
+LIMIT @page_limit
+OFFSET @page_offset;
+
+-- name: CountUserReservations :one
+SELECT COUNT(*) FROM reservations r
+JOIN passengers p ON r.passenger_id = p.id
+JOIN schedules s ON r.schedule_id = s.id
+WHERE (p.user_id = sqlc.narg('user_id') OR p.session_id = sqlc.narg('session_id'))
+  AND (cardinality(@statuses::text[]) = 0 OR r.reservation_status::text = ANY(@statuses::text[]))
+  AND (sqlc.narg('departure_from')::timestamp IS NULL OR s.departure_date >= sqlc.narg('departure_from')::timestamp)
+  AND (sqlc.narg('departure_to')::timestamp IS NULL OR s.departure_date < sqlc.narg('departure_to')::timestamp);
 
 -- name: CreateReservation :one
 INSERT INTO reservations (
-   passenger_id, schedule_id, wagon_id, seat_id, booking_date, reservation_status, discount_id, price, expires_at
+   passenger_id, schedule_id, wagon_id, seat_id, booking_date, reservation_status, discount_id, price, expires_at, order_id, from_stop, to_stop, passenger_type
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 ) 
 RETURNING *;
 
@@ -59,12 +177,13 @@ WHERE id = $1;
 
 -- name: DeleteReservation :exec
 DELETE FROM reservations
-WHERE id = $1 AND reservation_status = 'canceled';
+WHERE id = $1 AND reservation_status = 'cancelled';
 
 -- name: CheckSeatAvailability :one
 SELECT COUNT(*) FROM reservations 
-WHERE schedule_id = $1 AND wagon_id = $2 AND seat_id = $3
-  AND reservation_status IN ('pending', 'success');
+WHERE schedule_id = @schedule_id AND wagon_id = @wagon_id AND seat_id = @seat_id
+  AND reservation_status IN ('pending', 'success')
+  AND int4range(from_stop, to_stop) && int4range(@from_stop::int, @to_stop::int);
 
 
 -- -- name: HoldSeat :exec
@@ -90,21 +209,10 @@ WHERE schedule_id = $1 AND wagon_id = $2 AND seat_id = $3
 -- FROM deleted_hold
 -- RETURNING *;
 
--- name: ConfirmReservation :exec
+-- name: UpdateReservationStatus :execrows
 UPDATE reservations
-SET reservation_status = 'success', updated_at = NOW()
-WHERE id = $1 AND reservation_status = 'pending';
-
--- name: CancelReservation :exec
-UPDATE reservations
-SET reservation_status = 'cancelled', updated_at = NOW()
-WHERE id = $1 AND reservation_status = 'pending';
-
-
--- name: UpdateTrainCapacity :exec
-UPDATE schedules
-SET available_seats = available_seats - 1
-WHERE id = $1 AND available_seats > 0;
+SET reservation_status = @to_status, updated_at = NOW()
+WHERE id = @id AND reservation_status = @from_status;
 
 -- -- name: CleanupExpiredHolds :exec
 -- DELETE FROM seat_holds WHERE expires_at < NOW();
@@ -113,14 +221,16 @@ WHERE id = $1 AND available_seats > 0;
 -- DELETE FROM seat_holds
 -- WHERE expires_at < NOW();
 
--- name: ExpireUndpaidReservations :exec
-DELETE FROM reservations
-WHERE expires_at < NOW() AND reservation_status = 'pending';
+-- name: ListExpiredReservations :many
+SELECT * FROM reservations
+WHERE expires_at < NOW() AND reservation_status = 'pending'
+ORDER BY expires_at;
 
 
 -- name: GetFullReservation :one
 SELECT 
 r.id AS reservation_id,
+o.booking_code,
 p.name AS passenger_name,
 p.id_number AS passenger_id_number,
 u.name AS user_name,
@@ -143,6 +253,7 @@ py.amount AS payment_amount,
 py.payment_method,
 py.payment_status 
 FROM reservations r
+JOIN orders o ON r.order_id = o.id
 LEFT JOIN passengers p ON r.passenger_id = p.id
 LEFT JOIN users u ON p.user_id = u.id
 LEFT JOIN schedules s ON r.schedule_id = s.id
@@ -151,5 +262,10 @@ LEFT JOIN wagons w ON r.wagon_id = w.id
 LEFT JOIN trains t ON s.train_id = t.id
 LEFT JOIN routes rt ON s.route_id = rt.id
 LEFT JOIN discount_codes d ON r.discount_id = d.id
-LEFT JOIN payments py ON r.id = py.reservation_id
+LEFT JOIN LATERAL (
+  SELECT amount, payment_method, payment_status FROM payments
+  WHERE reservation_id = r.id OR (reservation_id IS NULL AND order_id = r.order_id)
+  ORDER BY created_at DESC
+  LIMIT 1
+) py ON true
 WHERE r.id = $1;
@@ -0,0 +1,11 @@
+-- name: CreateReservationStatusHistory :exec
+INSERT INTO reservation_status_history (
+   reservation_id, from_status, to_status, reason, actor
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- name: ListReservationStatusHistory :many
+SELECT * FROM reservation_status_history
+WHERE reservation_id = $1
+ORDER BY created_at, id;
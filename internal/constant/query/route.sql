@@ -26,3 +26,30 @@ WHERE id = $1;
 -- name: DeleteRoute :exec
 DELETE FROM routes
 WHERE id = $1;
+
+-- name: ListRouteStops :many
+SELECT * FROM route_stops
+WHERE route_id = $1
+ORDER BY stop_order;
+
+-- name: ListRouteStopsByRoutes :many
+SELECT * FROM route_stops
+WHERE route_id = ANY(@route_ids::bigint[])
+ORDER BY route_id, stop_order;
+
+-- name: CreateRouteStop :one
+INSERT INTO route_stops (
+   route_id, stop_order, station_code, minutes_from_start
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: DeleteRouteStops :exec
+DELETE FROM route_stops
+WHERE route_id = $1;
+
+-- name: CountActiveRouteReservations :one
+SELECT COUNT(*) FROM reservations r
+JOIN schedules s ON r.schedule_id = s.id
+WHERE s.route_id = $1 AND r.reservation_status IN ('pending', 'success');
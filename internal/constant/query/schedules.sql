@@ -10,7 +10,6 @@ SELECT
   r.destination_station AS destination_station,
   s.departure_date,
   s.arrival_date,
-  s.available_seats,
   s.price
 FROM schedules s
 JOIN routes r ON s.route_id = r.id
@@ -21,15 +20,33 @@ WHERE
   DATE(s.departure_date) = $3
 ORDER BY s.departure_date;
 
+-- name: ListDepartingSchedules :many
+SELECT
+  s.id,
+  s.train_id,
+  s.route_id,
+  s.departure_date,
+  s.arrival_date,
+  s.price,
+  t.name AS train_name,
+  rt.source_station,
+  rt.destination_station,
+  rt.travel_time
+FROM schedules s
+JOIN trains t ON s.train_id = t.id
+JOIN routes rt ON s.route_id = rt.id
+WHERE s.departure_date >= @window_start AND s.departure_date < @window_end
+ORDER BY s.departure_date;
+
 -- name: ListSchedules :many
 SELECT * FROM schedules
 ORDER BY departure_date;
 
 -- name: CreateSchedule :one
 INSERT INTO schedules (
-   train_id, departure_date, arrival_date, available_seats, price, route_id
+   train_id, departure_date, arrival_date, price, route_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    $1, $2, $3, $4, $5
 )
 RETURNING *;
 
@@ -39,10 +56,42 @@ UPDATE schedules
   route_id = $3,
   departure_date = $4,
   arrival_date = $5,
-  price = $6,
-  available_seats = $7
+  price = $6
 WHERE id = $1;
 
 -- name: DeleteSchedule :exec
 DELETE FROM schedules
 WHERE id = $1;
+
+-- name: ListScheduleAvailability :many
+SELECT
+  s.id AS schedule_id,
+  w.class_type,
+  COUNT(DISTINCT st.id) FILTER (WHERE st.is_available IS NOT FALSE) AS total_seats,
+  COUNT(DISTINCT st.id) FILTER (WHERE st.is_available IS NOT FALSE AND r.id IS NULL) AS available_seats
+FROM schedules s
+JOIN wagons w ON w.train_id = s.train_id
+JOIN seats st ON st.wagon_id = w.id
+LEFT JOIN reservations r ON r.schedule_id = s.id AND r.wagon_id = w.id AND r.seat_id = st.id
+  AND r.reservation_status IN ('pending', 'success')
+WHERE s.id = ANY(@schedule_ids::bigint[])
+GROUP BY s.id, w.class_type
+ORDER BY s.id, w.class_type;
+
+-- name: ListScheduleBookedLegs :many
+-- ListScheduleBookedLegs groups the bookable seats of the schedules by class and by the legs their
+-- pending and paid reservations occupy, bit n of booked_legs is leg n of the route.
+SELECT DISTINCT schedule_id, class_type, booked_legs FROM (
+  SELECT
+    s.id AS schedule_id,
+    w.class_type,
+    COALESCE(BIT_OR(((1::bigint << (r.to_stop - r.from_stop)) - 1) << r.from_stop), 0)::bigint AS booked_legs
+  FROM schedules s
+  JOIN wagons w ON w.train_id = s.train_id
+  JOIN seats st ON st.wagon_id = w.id
+  LEFT JOIN reservations r ON r.schedule_id = s.id AND r.wagon_id = w.id AND r.seat_id = st.id
+    AND r.reservation_status IN ('pending', 'success')
+  WHERE s.id = ANY(@schedule_ids::bigint[]) AND st.is_available IS NOT FALSE
+  GROUP BY s.id, w.class_type, st.id
+) seat_legs
+ORDER BY schedule_id, class_type;
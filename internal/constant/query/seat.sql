@@ -24,3 +24,24 @@ WHERE id = $1;
 -- name: DeleteSeat :exec
 DELETE FROM seats
 WHERE id = $1;
+
+-- name: ListScheduleSeats :many
+SELECT
+w.id AS wagon_id,
+w.wagon_number,
+w.class_type,
+st.id AS seat_id,
+st.seat_number,
+st.seat_row,
+st.is_available,
+COUNT(r.id) AS active_reservations,
+COALESCE(BOOL_OR(r.reservation_status = 'success'), false)::boolean AS paid
+FROM schedules s
+JOIN wagons w ON w.train_id = s.train_id
+JOIN seats st ON st.wagon_id = w.id
+LEFT JOIN reservations r ON r.schedule_id = s.id AND r.wagon_id = w.id AND r.seat_id = st.id
+  AND r.reservation_status IN ('pending', 'success')
+  AND int4range(r.from_stop, r.to_stop) && int4range(@from_stop::int, @to_stop::int)
+WHERE s.id = @schedule_id
+GROUP BY w.id, st.id
+ORDER BY w.wagon_number, w.id, st.seat_number, st.seat_row;
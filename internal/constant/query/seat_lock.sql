@@ -0,0 +1,29 @@
+-- name: AcquireSeatLock :execrows
+-- an expired lock is taken over in place, a live one makes the insert affect no row
+INSERT INTO seat_locks (
+  schedule_id, wagon_id, seat_id, owner, expires_at
+) VALUES (
+  @schedule_id, @wagon_id, @seat_id, @owner, CURRENT_TIMESTAMP + @ttl_ms::bigint * INTERVAL '1 millisecond'
+)
+ON CONFLICT (schedule_id, wagon_id, seat_id) DO UPDATE
+SET owner = EXCLUDED.owner, expires_at = EXCLUDED.expires_at, created_at = CURRENT_TIMESTAMP
+WHERE seat_locks.expires_at <= CURRENT_TIMESTAMP;
+
+-- name: ReleaseSeatLock :execrows
+DELETE FROM seat_locks
+WHERE schedule_id = $1 AND wagon_id = $2 AND seat_id = $3 AND owner = $4
+  AND expires_at > CURRENT_TIMESTAMP;
+
+-- name: RefreshSeatLock :execrows
+UPDATE seat_locks
+SET expires_at = CURRENT_TIMESTAMP + @ttl_ms::bigint * INTERVAL '1 millisecond'
+WHERE schedule_id = @schedule_id AND wagon_id = @wagon_id AND seat_id = @seat_id AND owner = @owner
+  AND expires_at > CURRENT_TIMESTAMP;
+
+-- name: DeleteExpiredSeatLocks :execrows
+DELETE FROM seat_locks
+WHERE expires_at <= CURRENT_TIMESTAMP;
+
+-- name: ListActiveSeatLocks :many
+SELECT wagon_id, seat_id FROM seat_locks
+WHERE schedule_id = $1 AND expires_at > CURRENT_TIMESTAMP;
@@ -0,0 +1,61 @@
+-- name: BoardTicket :one
+INSERT INTO ticket_boardings (
+   reservation_id, schedule_id, conductor_id, status, boarded_at
+) VALUES (
+    $1, $2, $3, 'boarded', NOW()
+)
+ON CONFLICT (reservation_id) DO UPDATE
+SET status = 'boarded', conductor_id = EXCLUDED.conductor_id, boarded_at = NOW(), updated_at = NOW()
+WHERE ticket_boardings.status <> 'boarded'
+RETURNING *;
+
+-- name: CheckInTicket :one
+INSERT INTO ticket_boardings (
+   reservation_id, schedule_id, status, checked_in_at
+) VALUES (
+    $1, $2, 'checked_in', NOW()
+)
+ON CONFLICT (reservation_id) DO NOTHING
+RETURNING *;
+
+-- name: GetTicketBoarding :one
+SELECT * FROM ticket_boardings
+WHERE reservation_id = $1 LIMIT 1;
+
+-- name: MarkNoShows :many
+INSERT INTO ticket_boardings (reservation_id, schedule_id, status)
+SELECT r.id, r.schedule_id, 'no_show'
+FROM reservations r
+JOIN schedules s ON r.schedule_id = s.id
+LEFT JOIN route_stops rs ON rs.route_id = s.route_id AND rs.stop_order = r.from_stop
+WHERE r.reservation_status = 'success'
+  AND s.departure_date + make_interval(mins => COALESCE(rs.minutes_from_start, 0) + @grace_minutes::int) < NOW()
+  AND s.departure_date >= @departed_after::timestamp
+  AND NOT EXISTS (
+    SELECT 1 FROM ticket_boardings b
+    WHERE b.reservation_id = r.id AND b.status <> 'checked_in'
+  )
+ON CONFLICT (reservation_id) DO UPDATE
+SET status = 'no_show', updated_at = NOW()
+WHERE ticket_boardings.status = 'checked_in'
+RETURNING reservation_id, schedule_id;
+
+-- name: NoShowReport :many
+SELECT
+  rt.id AS route_id,
+  rt.source_station,
+  rt.destination_station,
+  COUNT(*) AS tickets,
+  COUNT(*) FILTER (WHERE b.status = 'boarded') AS boarded,
+  COUNT(*) FILTER (WHERE b.status = 'no_show') AS no_shows,
+  COUNT(*) FILTER (WHERE b.checked_in_at IS NOT NULL) AS checked_in,
+  COUNT(*) FILTER (WHERE b.checked_in_at IS NOT NULL AND b.status = 'no_show') AS checked_in_no_shows
+FROM ticket_boardings b
+JOIN schedules s ON b.schedule_id = s.id
+JOIN routes rt ON s.route_id = rt.id
+WHERE b.status IN ('boarded', 'no_show')
+  AND (sqlc.narg('departure_from')::timestamp IS NULL OR s.departure_date >= sqlc.narg('departure_from')::timestamp)
+  AND (sqlc.narg('departure_to')::timestamp IS NULL OR s.departure_date < sqlc.narg('departure_to')::timestamp)
+  AND (sqlc.narg('route_id')::bigint IS NULL OR rt.id = sqlc.narg('route_id')::bigint)
+GROUP BY rt.id, rt.source_station, rt.destination_station
+ORDER BY rt.id;
@@ -24,10 +24,3 @@ WHERE id = $1;
 -- name: DeleteWagon :exec
 DELETE FROM wagons
 WHERE id = $1;
-
-
--- name: DecreaseWagonSeat :exec
-UPDATE wagons
-SET total_seats = total_seats - 1,
-updated_at = NOW()
-WHERE id = $1 AND total_seats > 0;
\ No newline at end of file
@@ -0,0 +1,33 @@
+-- name: CreateWaitlistEntry :one
+INSERT INTO waitlist_entries (
+   passenger_id, schedule_id, class_type, from_stop, to_stop
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetWaitlistEntry :one
+SELECT * FROM waitlist_entries
+WHERE id = $1 LIMIT 1;
+
+-- name: GetWaitlistPosition :one
+SELECT COUNT(*) FROM waitlist_entries w
+JOIN waitlist_entries e ON e.id = $1
+WHERE w.schedule_id = e.schedule_id AND w.class_type = e.class_type AND w.status = 'waiting'
+  AND (w.created_at < e.created_at OR (w.created_at = e.created_at AND w.id <= e.id));
+
+-- name: ListWaitingEntries :many
+SELECT * FROM waitlist_entries
+WHERE schedule_id = $1 AND status = 'waiting'
+ORDER BY created_at, id
+FOR UPDATE SKIP LOCKED;
+
+-- name: PromoteWaitlistEntry :exec
+UPDATE waitlist_entries
+SET status = 'promoted', reservation_id = $2, updated_at = NOW()
+WHERE id = $1 AND status = 'waiting';
+
+-- name: CancelWaitlistEntry :exec
+UPDATE waitlist_entries
+SET status = 'cancelled', updated_at = NOW()
+WHERE id = $1 AND status = 'waiting';
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"railway-go/internal/constant/model"
+	"railway-go/internal/usecase"
+	"railway-go/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type HoldControllers interface {
+	CreateHold(ctx *fiber.Ctx) error
+	ExtendHold(ctx *fiber.Ctx) error
+	ReleaseHold(ctx *fiber.Ctx) error
+}
+
+type HoldController struct {
+	Log     *zap.Logger
+	Usecase usecase.HoldUC
+}
+
+func NewHoldController(usecase usecase.HoldUC, log *zap.Logger) HoldControllers {
+	return &HoldController{
+		Log:     log,
+		Usecase: usecase,
+	}
+}
+
+// sessionID returns the id of the session attached by the auth middleware, holds are owned by it
+func sessionID(ctx *fiber.Ctx) string {
+	session, ok := ctx.Locals("session").(*model.Session)
+	if !ok || session == nil {
+		return ""
+	}
+	return session.ID
+}
+
+func (c *HoldController) CreateHold(ctx *fiber.Ctx) error {
+	request := new(model.HoldRequest)
+
+	if err := ctx.BodyParser(request); err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse request body")
+	}
+	request.SessionID = sessionID(ctx)
+
+	response, err := c.Usecase.CreateHold(ctx.UserContext(), *request)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.BuildSuccessResponse(response, nil))
+}
+
+func (c *HoldController) ExtendHold(ctx *fiber.Ctx) error {
+	response, err := c.Usecase.ExtendHold(ctx.UserContext(), ctx.Params("id"), sessionID(ctx))
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(response, nil))
+}
+
+func (c *HoldController) ReleaseHold(ctx *fiber.Ctx) error {
+	if err := c.Usecase.ReleaseHold(ctx.UserContext(), ctx.Params("id"), sessionID(ctx)); err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse("seat hold released", nil))
+}
@@ -0,0 +1,41 @@
+package http
+
+import (
+	"railway-go/internal/constant/model"
+	"railway-go/internal/usecase"
+	"railway-go/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type JourneyControllers interface {
+	SearchJourneys(ctx *fiber.Ctx) error
+}
+
+type JourneyController struct {
+	Log     *zap.Logger
+	Usecase usecase.JourneyUC
+}
+
+func NewJourneyController(usecase usecase.JourneyUC, log *zap.Logger) JourneyControllers {
+	return &JourneyController{
+		Log:     log,
+		Usecase: usecase,
+	}
+}
+
+func (c *JourneyController) SearchJourneys(ctx *fiber.Ctx) error {
+	request := new(model.JourneySearchRequest)
+
+	if err := ctx.QueryParser(request); err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse query")
+	}
+
+	response, err := c.Usecase.SearchJourneys(ctx.UserContext(), *request)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(response, nil))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"railway-go/internal/constant/model"
 	"railway-go/internal/usecase"
+	"railway-go/internal/utils"
 	"railway-go/internal/utils/token"
 	"time"
 
@@ -40,6 +41,7 @@ func (m *AuthMiddleware) AuthRequired() fiber.Handler {
 
 			// attach guest session to request context
 			c.Locals("session", &session)
+			c.SetUserContext(utils.WithSession(c.UserContext(), &session))
 			return c.Next()
 		}
 
@@ -49,7 +51,7 @@ func (m *AuthMiddleware) AuthRequired() fiber.Handler {
 			return c.Status(fiber.StatusUnauthorized).JSON(model.BuildErrorResponse("session not found or expired"))
 		}
 
-		if session.Role == "user" || session.Role == "admin" || session.Role == "general affairs" {
+		if session.Role == "user" || session.Role == "admin" || session.Role == "general affairs" || session.Role == "conductor" {
 			// Extract token from Authorization header
 			authHeader := c.Get("Authorization")
 			if authHeader == "" {
@@ -69,6 +71,7 @@ func (m *AuthMiddleware) AuthRequired() fiber.Handler {
 		}
 
 		c.Locals("session", &session)
+		c.SetUserContext(utils.WithSession(c.UserContext(), &session))
 
 		// fmt.Printf("session role :%s", session.Role)
 
@@ -121,3 +124,16 @@ func (m *AuthMiddleware) GeneralAffairs() fiber.Handler {
 		return c.Next()
 	}
 }
+
+func (m *AuthMiddleware) ConductorOnly() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		session, ok := c.Locals("session").(*model.Session)
+		if !ok || session == nil {
+			return c.Status(fiber.StatusForbidden).JSON(model.BuildErrorResponse("conductor or admin access required"))
+		}
+		if session.Role != "conductor" && session.Role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(model.BuildErrorResponse("conductor or admin access required"))
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"railway-go/internal/constant/model"
+	"railway-go/internal/usecase"
+	"railway-go/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const maxIdempotencyKeyLength = 255
+
+type IdempotencyMiddleware struct {
+	Usecase usecase.IdempotencyUC
+	Log     *zap.Logger
+}
+
+func NewIdempotencyMiddleware(usecase usecase.IdempotencyUC, log *zap.Logger) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		Usecase: usecase,
+		Log:     log,
+	}
+}
+
+// Idempotent makes a route safe to retry with an Idempotency-Key header. The first response
+// is stored under the session, the key and a hash of the request, and is replayed for every
+// retry of the same request. A retry arriving while the first request is still processed gets
+// a 409. Server errors are not stored so the request can be retried. Requests without the
+// header are passed through unchanged. Must run after AuthRequired.
+func (m *IdempotencyMiddleware) Idempotent() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get("Idempotency-Key")
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return c.Status(fiber.StatusBadRequest).JSON(model.BuildErrorResponse("idempotency key is too long"))
+		}
+
+		session, ok := c.Locals("session").(*model.Session)
+		if !ok || session == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(model.BuildErrorResponse("session is required"))
+		}
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Method()))
+		hash.Write([]byte(c.OriginalURL()))
+		hash.Write(c.Body())
+		scope := session.ID + ":" + key + ":" + hex.EncodeToString(hash.Sum(nil))
+
+		record, replay, err := m.Usecase.BeginRequest(c.UserContext(), scope)
+		if err != nil {
+			return utils.HandleError(c, m.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+		}
+		if replay {
+			c.Set("Idempotent-Replayed", "true")
+			c.Set(fiber.HeaderContentType, record.ContentType)
+			return c.Status(record.StatusCode).Send(record.Body)
+		}
+
+		if err := c.Next(); err != nil {
+			m.Usecase.AbandonRequest(c.UserContext(), scope)
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			m.Usecase.AbandonRequest(c.UserContext(), scope)
+			return nil
+		}
+
+		if err := m.Usecase.CompleteRequest(c.UserContext(), scope, model.IdempotencyRecord{
+			StatusCode:  status,
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        append([]byte(nil), c.Response().Body()...),
+		}); err != nil {
+			// the response was produced already, a retry will only be processed again
+			m.Usecase.AbandonRequest(c.UserContext(), scope)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,155 @@
+package http
+
+import (
+	"railway-go/internal/constant/model"
+	"railway-go/internal/usecase"
+	"railway-go/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type OrderControllers interface {
+	CreateOrder(ctx *fiber.Ctx) error
+	GetOrderByCode(ctx *fiber.Ctx) error
+	CreateAutoOrder(ctx *fiber.Ctx) error
+	CreateItineraryOrder(ctx *fiber.Ctx) error
+	UpdateBookingContact(ctx *fiber.Ctx) error
+	RequestBookingAccess(ctx *fiber.Ctx) error
+	VerifyBookingAccess(ctx *fiber.Ctx) error
+}
+
+type OrderController struct {
+	Log     *zap.Logger
+	Usecase usecase.OrderUC
+}
+
+func NewOrderController(usecase usecase.OrderUC, log *zap.Logger) OrderControllers {
+	return &OrderController{
+		Log:     log,
+		Usecase: usecase,
+	}
+}
+
+func (c *OrderController) CreateOrder(ctx *fiber.Ctx) error {
+	request := new(model.OrderRequest)
+
+	if err := ctx.BodyParser(request); err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	// validate required fields
+	if request.ScheduleID == 0 || len(request.Items) == 0 {
+		return utils.HandleError(ctx, c.Log, nil, fiber.StatusBadRequest, "schedule_id and at least one item are required")
+	}
+
+	request.SessionID = sessionID(ctx)
+
+	response, err := c.Usecase.CreateOrder(ctx.UserContext(), *request)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.BuildSuccessResponse(response, nil))
+}
+
+func (c *OrderController) GetOrderByCode(ctx *fiber.Ctx) error {
+	code := ctx.Params("code")
+	if code == "" {
+		return utils.HandleError(ctx, c.Log, nil, fiber.StatusBadRequest, "booking code is required")
+	}
+
+	response, err := c.Usecase.GetOrderByCode(ctx.UserContext(), code)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(response, nil))
+}
+
+func (c *OrderController) CreateAutoOrder(ctx *fiber.Ctx) error {
+	request := new(model.AutoOrderRequest)
+
+	if err := ctx.BodyParser(request); err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	// validate required fields
+	if request.ScheduleID == 0 || request.ClassType == "" || len(request.PassengerIDs) == 0 {
+		return utils.HandleError(ctx, c.Log, nil, fiber.StatusBadRequest, "schedule_id, class_type and at least one passenger are required")
+	}
+
+	request.SessionID = sessionID(ctx)
+
+	response, err := c.Usecase.CreateAutoOrder(ctx.UserContext(), *request)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.BuildSuccessResponse(response, nil))
+}
+
+func (c *OrderController) CreateItineraryOrder(ctx *fiber.Ctx) error {
+	request := new(model.ItineraryRequest)
+
+	if err := ctx.BodyParser(request); err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	if len(request.Legs) == 0 {
+		return utils.HandleError(ctx, c.Log, nil, fiber.StatusBadRequest, "at least one leg is required")
+	}
+
+	request.SessionID = sessionID(ctx)
+
+	response, err := c.Usecase.CreateItineraryOrder(ctx.UserContext(), *request)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.BuildSuccessResponse(response, nil))
+}
+
+func (c *OrderController) UpdateBookingContact(ctx *fiber.Ctx) error {
+	request := new(model.BookingContactRequest)
+
+	if err := ctx.BodyParser(request); err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	response, err := c.Usecase.UpdateBookingContact(ctx.UserContext(), ctx.Params("code"), *request)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(response, nil))
+}
+
+func (c *OrderController) RequestBookingAccess(ctx *fiber.Ctx) error {
+	request := new(model.BookingAccessRequest)
+
+	if err := ctx.BodyParser(request); err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	if err := c.Usecase.RequestBookingAccess(ctx.UserContext(), *request); err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(model.BuildSuccessResponse("if the booking exists, a verification code was sent to its contact email", nil))
+}
+
+func (c *OrderController) VerifyBookingAccess(ctx *fiber.Ctx) error {
+	request := new(model.BookingVerifyRequest)
+
+	if err := ctx.BodyParser(request); err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	response, err := c.Usecase.VerifyBookingAccess(ctx.UserContext(), *request)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(response, nil))
+}
@@ -71,7 +71,7 @@ func (c *PassengerController) CreatePassenger(ctx *fiber.Ctx) error {
 
 	response, err := c.Usecase.CreatePassenger(ctx.UserContext(), *request)
 	if err != nil {
-		return utils.HandleError(ctx, c.Log, err, fiber.StatusInternalServerError, "failed to create passenger")
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), "failed to create passenger")
 	}
 
 	return ctx.Status(fiber.StatusCreated).JSON(model.BuildSuccessResponse(response, nil))
@@ -83,9 +83,14 @@ func (c *PassengerController) GetPassenger(ctx *fiber.Ctx) error {
 		return utils.HandleError(ctx, c.Log, nil, fiber.StatusBadRequest, "passenger id is required")
 	}
 
-	response, err := c.Usecase.GetPassenger(ctx.UserContext(), uuid.MustParse(request))
+	passengerID, err := uuid.Parse(request)
 	if err != nil {
-		return utils.HandleError(ctx, c.Log, err, fiber.StatusInternalServerError, "failed to get passenger")
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "invalid passenger id")
+	}
+
+	response, err := c.Usecase.GetPassenger(ctx.UserContext(), passengerID)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), "failed to get passenger")
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(response, nil))
@@ -96,7 +101,10 @@ func (c *PassengerController) UpdatePassenger(ctx *fiber.Ctx) error {
 	if id == "" {
 		return utils.HandleError(ctx, c.Log, nil, fiber.StatusBadRequest, "passenger id is required")
 	}
-	parsed, _ := uuid.Parse(id)
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "invalid passenger id")
+	}
 	passengerRequest := new(model.PassengerRequest)
 	if err := ctx.BodyParser(passengerRequest); err != nil {
 		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse request body")
@@ -105,7 +113,7 @@ func (c *PassengerController) UpdatePassenger(ctx *fiber.Ctx) error {
 	passengerRequest.ID = parsed
 
 	if err := c.Usecase.UpdatePassenger(ctx.UserContext(), *passengerRequest); err != nil {
-		return utils.HandleError(ctx, c.Log, err, fiber.StatusInternalServerError, "failed to update passenger")
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), "failed to update passenger")
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse("Passenger updated successfully", nil))
@@ -117,9 +125,14 @@ func (c *PassengerController) DeletePassenger(ctx *fiber.Ctx) error {
 		return utils.HandleError(ctx, c.Log, nil, fiber.StatusBadRequest, "passenger id is required")
 	}
 
-	err := c.Usecase.DeletePassenger(ctx.UserContext(), uuid.MustParse(request))
+	passengerID, err := uuid.Parse(request)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "invalid passenger id")
+	}
+
+	err = c.Usecase.DeletePassenger(ctx.UserContext(), passengerID)
 	if err != nil {
-		return utils.HandleError(ctx, c.Log, err, fiber.StatusInternalServerError, "failed to delete passenger")
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), "failed to delete passenger")
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse("Passenger deleted successfully", nil))
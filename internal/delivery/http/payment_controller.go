@@ -11,6 +11,7 @@ import (
 
 type PaymentControllers interface {
 	MockPaymentWebhook(ctx *fiber.Ctx) error
+	MockOrderPaymentWebhook(ctx *fiber.Ctx) error
 }
 
 type PaymentController struct {
@@ -38,9 +39,32 @@ func (c *PaymentController) MockPaymentWebhook(ctx *fiber.Ctx) error {
 		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "All fields are required")
 	}
 
+	req.SessionID = sessionID(ctx)
+
 	response, err := c.Usecase.ProcessMockPayment(ctx.UserContext(), *req)
 	if err != nil {
-		return utils.HandleError(ctx, c.Log, err, fiber.StatusInternalServerError, "failed to process payment")
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), "failed to process payment")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(response, nil))
+}
+
+func (c *PaymentController) MockOrderPaymentWebhook(ctx *fiber.Ctx) error {
+	req := new(model.OrderPaymentRequest)
+
+	if err := ctx.BodyParser(req); err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	if req.PaymentMethod == "" || req.Amount == 0 {
+		return utils.HandleError(ctx, c.Log, nil, fiber.StatusBadRequest, "All fields are required")
+	}
+
+	req.SessionID = sessionID(ctx)
+
+	response, err := c.Usecase.ProcessMockOrderPayment(ctx.UserContext(), ctx.Params("code"), *req)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(response, nil))
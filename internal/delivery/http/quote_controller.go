@@ -0,0 +1,42 @@
+package http
+
+import (
+	"railway-go/internal/constant/model"
+	"railway-go/internal/usecase"
+	"railway-go/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type QuoteControllers interface {
+	CreateQuote(ctx *fiber.Ctx) error
+}
+
+type QuoteController struct {
+	Log     *zap.Logger
+	Usecase usecase.QuoteUC
+}
+
+func NewQuoteController(usecase usecase.QuoteUC, log *zap.Logger) QuoteControllers {
+	return &QuoteController{
+		Log:     log,
+		Usecase: usecase,
+	}
+}
+
+func (c *QuoteController) CreateQuote(ctx *fiber.Ctx) error {
+	request := new(model.QuoteRequest)
+
+	if err := ctx.BodyParser(request); err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse request body")
+	}
+	request.SessionID = sessionID(ctx)
+
+	response, err := c.Usecase.CreateQuote(ctx.UserContext(), *request)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.BuildSuccessResponse(response, nil))
+}
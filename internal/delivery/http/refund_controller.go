@@ -0,0 +1,41 @@
+package http
+
+import (
+	"railway-go/internal/constant/model"
+	"railway-go/internal/usecase"
+	"railway-go/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type RefundControllers interface {
+	RefundReservation(ctx *fiber.Ctx) error
+}
+
+type RefundController struct {
+	Log     *zap.Logger
+	Usecase usecase.RefundUC
+}
+
+func NewRefundController(usecase usecase.RefundUC, log *zap.Logger) RefundControllers {
+	return &RefundController{
+		Log:     log,
+		Usecase: usecase,
+	}
+}
+
+func (c *RefundController) RefundReservation(ctx *fiber.Ctx) error {
+	request := new(model.RefundRequest)
+
+	if err := ctx.BodyParser(request); err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	response, err := c.Usecase.RefundReservation(ctx.UserContext(), *request)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.BuildSuccessResponse(response, nil))
+}
@@ -1,7 +1,8 @@
 package http
 
 import (
-	"math"
+	"bufio"
+	"fmt"
 	"railway-go/internal/constant/model"
 	"railway-go/internal/usecase"
 	"railway-go/internal/utils"
@@ -18,6 +19,9 @@ type ReservationControllers interface {
 	CancelReservation(ctx *fiber.Ctx) error
 	DeleteReservation(ctx *fiber.Ctx) error
 	GetAllReservations(ctx *fiber.Ctx) error
+	ExportReservations(ctx *fiber.Ctx) error
+	GetMyReservations(ctx *fiber.Ctx) error
+	ExchangeReservation(ctx *fiber.Ctx) error
 }
 
 type ReservationController struct {
@@ -43,7 +47,7 @@ func (c *ReservationController) CreateReservation(ctx *fiber.Ctx) error {
 	}
 
 	// validate required fields
-	if request.ScheduleID == 0 || request.WagonID == 0 || request.Seat_id == 0 {
+	if request.HoldID == "" && (request.ScheduleID == 0 || request.WagonID == 0 || request.Seat_id == 0) {
 		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "All fields are required")
 	}
 
@@ -59,6 +63,8 @@ func (c *ReservationController) CreateReservation(ctx *fiber.Ctx) error {
 		return utils.HandleError(ctx, c.Log, err, fiber.StatusNotFound, "failed to get session")
 	}
 
+	request.SessionID = session.ID
+
 	if session.Role == "user" || session.Role == "admin" {
 		userID, err := c.UserUC.GetUserIDFromSession(ctx.UserContext(), session.ID)
 		if err != nil {
@@ -72,7 +78,7 @@ func (c *ReservationController) CreateReservation(ctx *fiber.Ctx) error {
 	response, err := c.Usecase.CreateReservation(ctx.UserContext(), *request)
 
 	if err != nil {
-		return utils.HandleError(ctx, c.Log, err, fiber.StatusInternalServerError, err.Error())
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
 	}
 
 	return ctx.Status(fiber.StatusCreated).JSON(model.BuildSuccessResponse(response, nil))
@@ -92,7 +98,7 @@ func (c *ReservationController) GetDetailReservation(ctx *fiber.Ctx) error {
 
 	response, err := c.Usecase.GetDetailReservation(ctx.UserContext(), reservationID)
 	if err != nil {
-		return utils.HandleError(ctx, c.Log, err, fiber.StatusInternalServerError, "failed to get reservation detail")
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), "failed to get reservation detail")
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(response, nil))
@@ -112,7 +118,7 @@ func (c *ReservationController) CancelReservation(ctx *fiber.Ctx) error {
 
 	err = c.Usecase.CancelReservation(ctx.UserContext(), reservationID)
 	if err != nil {
-		return utils.HandleError(ctx, c.Log, err, fiber.StatusInternalServerError, "failed to cancel reservation")
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), "failed to cancel reservation")
 	}
 
 	return ctx.SendStatus(fiber.StatusNoContent)
@@ -132,25 +138,78 @@ func (c *ReservationController) DeleteReservation(ctx *fiber.Ctx) error {
 
 	err = c.Usecase.DeleteReservation(ctx.UserContext(), reservationID)
 	if err != nil {
-		return utils.HandleError(ctx, c.Log, err, fiber.StatusInternalServerError, err.Error())
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse("successful delete reservation", nil))
 }
 
 func (c *ReservationController) GetAllReservations(ctx *fiber.Ctx) error {
+	request := new(model.ReservationSearchRequest)
+
+	if err := ctx.QueryParser(request); err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse query")
+	}
+
+	response, paging, err := c.Usecase.GetAllReservations(ctx.UserContext(), *request)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), "failed to get all reservations")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(response, &paging))
+}
+
+// ExportReservations streams the reservations matching the admin search as a CSV file.
+func (c *ReservationController) ExportReservations(ctx *fiber.Ctx) error {
+	request := new(model.ReservationSearchRequest)
+
+	if err := ctx.QueryParser(request); err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse query")
+	}
+
+	export, err := c.Usecase.ExportReservations(ctx.UserContext(), *request)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
 
-	response, total, err := c.Usecase.GetAllReservations(ctx.UserContext())
+	ctx.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	ctx.Attachment(fmt.Sprintf("reservations-%s.csv", time.Now().Format("20060102-150405")))
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		// the status is sent already, a failure can only cut the file short
+		if err := export(w); err != nil {
+			c.Log.Error("failed to export reservations", zap.Error(err))
+		}
+	})
+	return nil
+}
+
+func (c *ReservationController) GetMyReservations(ctx *fiber.Ctx) error {
+	request := new(model.MyReservationsRequest)
+
+	if err := ctx.QueryParser(request); err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse query")
+	}
+
+	response, paging, err := c.Usecase.GetMyReservations(ctx.UserContext(), *request)
 	if err != nil {
-		return utils.HandleError(ctx, c.Log, err, fiber.StatusInternalServerError, "failed to get all reservations")
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(response, &paging))
+}
+
+func (c *ReservationController) ExchangeReservation(ctx *fiber.Ctx) error {
+	request := new(model.ExchangeRequest)
+
+	if err := ctx.BodyParser(request); err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse request body")
 	}
+	request.SessionID = sessionID(ctx)
 
-	paging := &model.PageMetaData{
-		Page:      1,
-		Size:      10,
-		TotalItem: total,
-		TotalPage: int64(math.Ceil(float64(total) / float64(10))),
+	response, err := c.Usecase.ExchangeReservation(ctx.UserContext(), *request)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(response, paging))
+	return ctx.Status(fiber.StatusCreated).JSON(model.BuildSuccessResponse(response, nil))
 }
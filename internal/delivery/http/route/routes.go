@@ -20,7 +20,15 @@ type RouteConfig struct {
 	WagonController       http.WagonControllers
 	DiscountController    http.DiscountControllers
 	StationController     http.StationControllers
+	OrderController       http.OrderControllers
+	WaitlistController    http.WaitlistControllers
+	HoldController        http.HoldControllers
+	QuoteController       http.QuoteControllers
+	RefundController      http.RefundControllers
+	JourneyController     http.JourneyControllers
+	TicketController      http.TicketControllers
 	AuthMiddleware        *middleware.AuthMiddleware
+	IdempotencyMiddleware *middleware.IdempotencyMiddleware
 }
 
 func (c *RouteConfig) Setup() {
@@ -36,14 +44,42 @@ func (c *RouteConfig) SetupAuthRoute() {
 
 	// Authenticated user routes
 	auth := c.App.Group("/auth", c.AuthMiddleware.AuthRequired())
-	auth.Post("/reservations", c.ReservationController.CreateReservation)
+	auth.Post("/reservations", c.IdempotencyMiddleware.Idempotent(), c.ReservationController.CreateReservation)
 	auth.Get("/reservations", c.ReservationController.GetDetailReservation)
+	auth.Get("/me/reservations", c.ReservationController.GetMyReservations)
 	auth.Delete("/reservations", c.ReservationController.DeleteReservation)
 	auth.Put("/reservations/_canceled", c.ReservationController.CancelReservation)
-	auth.Post("/reservations/payments", c.PaymentController.MockPaymentWebhook)
+	auth.Post("/reservations/exchange", c.ReservationController.ExchangeReservation)
+	auth.Post("/reservations/refund", c.RefundController.RefundReservation)
+	auth.Post("/reservations/payments", c.IdempotencyMiddleware.Idempotent(), c.PaymentController.MockPaymentWebhook)
+	auth.Get("/reservations/:id/ticket", c.TicketController.DownloadTicket)
+	auth.Get("/reservations/:id/ticket/token", c.TicketController.GetTicketToken)
+	auth.Post("/reservations/:id/check-in", c.TicketController.CheckInTicket)
+
+	auth.Post("/holds", c.HoldController.CreateHold)
+	auth.Put("/holds/:id", c.HoldController.ExtendHold)
+	auth.Delete("/holds/:id", c.HoldController.ReleaseHold)
+
+	auth.Post("/quotes", c.QuoteController.CreateQuote)
+
+	auth.Post("/orders", c.OrderController.CreateOrder)
+	auth.Post("/orders/auto", c.OrderController.CreateAutoOrder)
+	auth.Post("/orders/itinerary", c.OrderController.CreateItineraryOrder)
+	auth.Post("/orders/access", c.OrderController.RequestBookingAccess)
+	auth.Post("/orders/access/verify", c.OrderController.VerifyBookingAccess)
+	auth.Get("/orders/:code", c.OrderController.GetOrderByCode)
+	auth.Put("/orders/:code/contact", c.OrderController.UpdateBookingContact)
+	auth.Post("/orders/:code/payments", c.PaymentController.MockOrderPaymentWebhook)
+
+	auth.Post("/waitlist", c.WaitlistController.JoinWaitlist)
+	auth.Get("/waitlist/:id", c.WaitlistController.GetWaitlistEntry)
+	auth.Delete("/waitlist/:id", c.WaitlistController.LeaveWaitlist)
 
 	auth.Get("/schedules", c.ScheduleController.GetSchedule)
 	auth.Get("/schedules/search", c.ScheduleController.SearchSchedules)
+	auth.Post("/schedules/search/itinerary", c.ScheduleController.SearchItinerary)
+	auth.Get("/journeys", c.JourneyController.SearchJourneys)
+	auth.Get("/schedules/:id/seatmap", c.ScheduleController.GetSeatMap)
 
 	auth.Post("/passengers", c.PassengerController.CreatePassenger)
 	auth.Get("/passengers", c.PassengerController.GetPassenger)
@@ -53,6 +89,13 @@ func (c *RouteConfig) SetupAuthRoute() {
 	// Admin routes
 	admin := c.App.Group("/admin", c.AuthMiddleware.AuthRequired(), c.AuthMiddleware.AdminOnly())
 	admin.Get("/reservations", c.ReservationController.GetAllReservations)
+	admin.Get("/reservations/export", c.ReservationController.ExportReservations)
+	admin.Get("/reports/no-shows", c.TicketController.GetNoShowReport)
+
+	// Conductor routes
+	conductor := c.App.Group("/conductor", c.AuthMiddleware.AuthRequired(), c.AuthMiddleware.ConductorOnly())
+	conductor.Get("/tickets/public-key", c.TicketController.GetPublicKey)
+	conductor.Post("/boardings", c.TicketController.BoardTicket)
 
 	// General Affairs routes
 	ga := c.App.Group("/ga", c.AuthMiddleware.AuthRequired(), c.AuthMiddleware.GeneralAffairs())
@@ -65,6 +108,8 @@ func (c *RouteConfig) SetupAuthRoute() {
 	ga.Put("/train_routes", c.RouteController.UpdateRoute)
 	ga.Delete("/train_routes", c.RouteController.DeleteRoute)
 	ga.Get("/train_routes/list", c.RouteController.GetRoutes)
+	ga.Get("/train_routes/stops", c.RouteController.GetRouteStops)
+	ga.Put("/train_routes/stops", c.RouteController.SetRouteStops)
 
 	ga.Post("/train_seats", c.SeatController.CreateSeat)
 	ga.Get("/train_seats", c.SeatController.GetSeat)
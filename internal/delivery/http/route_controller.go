@@ -16,6 +16,8 @@ type RouteControllers interface {
 	GetRoutes(ctx *fiber.Ctx) error
 	UpdateRoute(ctx *fiber.Ctx) error
 	DeleteRoute(ctx *fiber.Ctx) error
+	GetRouteStops(ctx *fiber.Ctx) error
+	SetRouteStops(ctx *fiber.Ctx) error
 }
 
 type RouteController struct {
@@ -118,3 +120,36 @@ func (c *RouteController) DeleteRoute(ctx *fiber.Ctx) error {
 
 	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse("Route deleted successfully", nil))
 }
+
+func (c *RouteController) GetRouteStops(ctx *fiber.Ctx) error {
+	routeID := ctx.QueryInt("id")
+	if routeID == 0 {
+		return utils.HandleError(ctx, c.Log, nil, fiber.StatusBadRequest, "route id is required")
+	}
+
+	response, err := c.Usecase.GetRouteStops(ctx.UserContext(), int64(routeID))
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), "failed to get route stops")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(response, nil))
+}
+
+func (c *RouteController) SetRouteStops(ctx *fiber.Ctx) error {
+	routeID := ctx.QueryInt("id")
+	if routeID == 0 {
+		return utils.HandleError(ctx, c.Log, nil, fiber.StatusBadRequest, "route id is required")
+	}
+
+	request := new(model.RouteStopsRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	response, err := c.Usecase.SetRouteStops(ctx.UserContext(), int64(routeID), *request)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(response, nil))
+}
@@ -17,6 +17,8 @@ type ScheduleControllers interface {
 	GetSchedule(ctx *fiber.Ctx) error
 	DeleteSchedule(ctx *fiber.Ctx) error
 	SearchSchedules(ctx *fiber.Ctx) error
+	SearchItinerary(ctx *fiber.Ctx) error
+	GetSeatMap(ctx *fiber.Ctx) error
 }
 
 type ScheduleController struct {
@@ -133,3 +135,32 @@ func (c *ScheduleController) SearchSchedules(ctx *fiber.Ctx) error {
 
 	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(response, nil))
 }
+
+func (c *ScheduleController) SearchItinerary(ctx *fiber.Ctx) error {
+	request := new(model.ItinerarySearchRequest)
+
+	if err := ctx.BodyParser(request); err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	response, err := c.Usecase.SearchItinerary(ctx.UserContext(), request)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(response, nil))
+}
+
+func (c *ScheduleController) GetSeatMap(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "invalid schedule id")
+	}
+
+	response, err := c.Usecase.GetSeatMap(ctx.UserContext(), int64(id), ctx.Query("from"), ctx.Query("to"))
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), "failed to get seat map")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(response, nil))
+}
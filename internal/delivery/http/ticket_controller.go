@@ -0,0 +1,111 @@
+package http
+
+import (
+	"fmt"
+	"railway-go/internal/constant/model"
+	"railway-go/internal/usecase"
+	"railway-go/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type TicketControllers interface {
+	DownloadTicket(ctx *fiber.Ctx) error
+	GetTicketToken(ctx *fiber.Ctx) error
+	BoardTicket(ctx *fiber.Ctx) error
+	GetPublicKey(ctx *fiber.Ctx) error
+	CheckInTicket(ctx *fiber.Ctx) error
+	GetNoShowReport(ctx *fiber.Ctx) error
+}
+
+type TicketController struct {
+	Log     *zap.Logger
+	Usecase usecase.TicketUC
+}
+
+func NewTicketController(usecase usecase.TicketUC, log *zap.Logger) TicketControllers {
+	return &TicketController{
+		Log:     log,
+		Usecase: usecase,
+	}
+}
+
+func (c *TicketController) DownloadTicket(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "invalid reservation id")
+	}
+
+	pdf, err := c.Usecase.GetTicketPDF(ctx.UserContext(), id)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	ctx.Set(fiber.HeaderContentType, "application/pdf")
+	ctx.Attachment(fmt.Sprintf("ticket-%s.pdf", id))
+	return ctx.Status(fiber.StatusOK).Send(pdf)
+}
+
+func (c *TicketController) GetTicketToken(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "invalid reservation id")
+	}
+
+	response, err := c.Usecase.GetTicketToken(ctx.UserContext(), id)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(response, nil))
+}
+
+func (c *TicketController) BoardTicket(ctx *fiber.Ctx) error {
+	request := new(model.BoardingRequest)
+
+	if err := ctx.BodyParser(request); err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	response, err := c.Usecase.BoardTicket(ctx.UserContext(), *request)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.BuildSuccessResponse(response, nil))
+}
+
+func (c *TicketController) GetPublicKey(ctx *fiber.Ctx) error {
+	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(c.Usecase.GetTicketPublicKey(), nil))
+}
+
+func (c *TicketController) CheckInTicket(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "invalid reservation id")
+	}
+
+	response, err := c.Usecase.CheckInTicket(ctx.UserContext(), id)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.BuildSuccessResponse(response, nil))
+}
+
+func (c *TicketController) GetNoShowReport(ctx *fiber.Ctx) error {
+	request := new(model.NoShowReportRequest)
+
+	if err := ctx.QueryParser(request); err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse query")
+	}
+
+	response, err := c.Usecase.GetNoShowReport(ctx.UserContext(), *request)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(response, nil))
+}
@@ -43,6 +43,7 @@ func (c *UserController) Register(ctx *fiber.Ctx) error {
 	if request.Name == "" || request.Email == "" || request.Password == "" {
 		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "All fields are required")
 	}
+	request.GuestSessionID = ctx.Cookies("session_id")
 
 	err = c.Usecase.Register(ctx.UserContext(), request)
 	if err != nil {
@@ -88,6 +89,7 @@ func (c *UserController) Login(ctx *fiber.Ctx) error {
 		c.Log.Warn("Failed to parse request body: %+v", zap.Error(err))
 		return ctx.Status(fiber.StatusBadRequest).JSON(model.BuildErrorResponse("Invalid request body"))
 	}
+	request.GuestSessionID = ctx.Cookies("session_id")
 
 	const fiberCtx string = "fiberCtx"
 	ctxWithFiber := context.WithValue(ctx.UserContext(), fiberCtx, ctx)
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"railway-go/internal/constant/model"
+	"railway-go/internal/usecase"
+	"railway-go/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type WaitlistControllers interface {
+	JoinWaitlist(ctx *fiber.Ctx) error
+	GetWaitlistEntry(ctx *fiber.Ctx) error
+	LeaveWaitlist(ctx *fiber.Ctx) error
+}
+
+type WaitlistController struct {
+	Log     *zap.Logger
+	Usecase usecase.WaitlistUC
+}
+
+func NewWaitlistController(usecase usecase.WaitlistUC, log *zap.Logger) WaitlistControllers {
+	return &WaitlistController{
+		Log:     log,
+		Usecase: usecase,
+	}
+}
+
+func (c *WaitlistController) JoinWaitlist(ctx *fiber.Ctx) error {
+	request := new(model.WaitlistRequest)
+
+	if err := ctx.BodyParser(request); err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	response, err := c.Usecase.JoinWaitlist(ctx.UserContext(), *request)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.BuildSuccessResponse(response, nil))
+}
+
+func (c *WaitlistController) GetWaitlistEntry(ctx *fiber.Ctx) error {
+	entryID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "invalid waitlist entry id")
+	}
+
+	response, err := c.Usecase.GetWaitlistEntry(ctx.UserContext(), entryID)
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse(response, nil))
+}
+
+func (c *WaitlistController) LeaveWaitlist(ctx *fiber.Ctx) error {
+	entryID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.HandleError(ctx, c.Log, err, fiber.StatusBadRequest, "invalid waitlist entry id")
+	}
+
+	if err := c.Usecase.LeaveWaitlist(ctx.UserContext(), entryID); err != nil {
+		return utils.HandleError(ctx, c.Log, err, utils.StatusCode(err, fiber.StatusInternalServerError), err.Error())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.BuildSuccessResponse("left waitlist", nil))
+}
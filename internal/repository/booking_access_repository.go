@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"railway-go/internal/constant/model"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+type BookingAccessRepository interface {
+	SaveBookingVerification(ctx context.Context, bookingCode string, verification model.BookingVerification, ttl time.Duration) error
+	GetBookingVerification(ctx context.Context, bookingCode string) (model.BookingVerification, error)
+	DeleteBookingVerification(ctx context.Context, bookingCode string) error
+	CountVerificationAttempt(ctx context.Context, bookingCode string, window time.Duration) (int64, error)
+	CountVerificationSent(ctx context.Context, bookingCode string, window time.Duration) (int64, error)
+	StartVerificationCooldown(ctx context.Context, bookingCode, email string, ttl time.Duration) (bool, error)
+	GrantBookingAccess(ctx context.Context, sessionID string, orderID uuid.UUID, ttl time.Duration) error
+	HasBookingAccess(ctx context.Context, sessionID string, orderID uuid.UUID) (bool, error)
+}
+
+const (
+	bookingVerificationKey         = "booking_verification:%s"
+	bookingVerificationAttemptsKey = "booking_verification_attempts:%s"
+	bookingVerificationSentKey     = "booking_verification_sent:%s"
+	bookingVerificationCooldownKey = "booking_verification_cooldown:%s:%s"
+	bookingAccessKey               = "booking_access:%s"
+)
+
+// countScript counts one more event in a fixed window, the window starts with the first event.
+var countScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// SaveBookingVerification stores a new verification of a booking, replacing the previous code.
+// The attempts made on earlier codes keep counting.
+func (r *redisRepository) SaveBookingVerification(ctx context.Context, bookingCode string, verification model.BookingVerification, ttl time.Duration) error {
+	verificationJson, err := json.Marshal(verification)
+	if err != nil {
+		return err
+	}
+	return r.RedisClient.Set(ctx, fmt.Sprintf(bookingVerificationKey, bookingCode), verificationJson, ttl).Err()
+}
+
+func (r *redisRepository) GetBookingVerification(ctx context.Context, bookingCode string) (model.BookingVerification, error) {
+	val, err := r.RedisClient.Get(ctx, fmt.Sprintf(bookingVerificationKey, bookingCode)).Bytes()
+	if err == redis.Nil {
+		return model.BookingVerification{}, model.ErrVerificationNotFound
+	} else if err != nil {
+		return model.BookingVerification{}, err
+	}
+
+	var verification model.BookingVerification
+	if err := json.Unmarshal(val, &verification); err != nil {
+		return model.BookingVerification{}, err
+	}
+	return verification, nil
+}
+
+func (r *redisRepository) DeleteBookingVerification(ctx context.Context, bookingCode string) error {
+	return r.RedisClient.Del(ctx, fmt.Sprintf(bookingVerificationKey, bookingCode)).Err()
+}
+
+// CountVerificationAttempt counts one more attempt at the codes of a booking and returns the
+// attempts made in the current window, concurrent attempts are counted atomically.
+func (r *redisRepository) CountVerificationAttempt(ctx context.Context, bookingCode string, window time.Duration) (int64, error) {
+	return countScript.Run(ctx, r.RedisClient, []string{fmt.Sprintf(bookingVerificationAttemptsKey, bookingCode)}, window.Milliseconds()).Int64()
+}
+
+// CountVerificationSent counts one more code sent for a booking and returns the codes sent in the current window.
+func (r *redisRepository) CountVerificationSent(ctx context.Context, bookingCode string, window time.Duration) (int64, error) {
+	return countScript.Run(ctx, r.RedisClient, []string{fmt.Sprintf(bookingVerificationSentKey, bookingCode)}, window.Milliseconds()).Int64()
+}
+
+// StartVerificationCooldown reports whether a code may be sent for the booking code and email,
+// and if so holds off the next one for the ttl.
+func (r *redisRepository) StartVerificationCooldown(ctx context.Context, bookingCode, email string, ttl time.Duration) (bool, error) {
+	return r.RedisClient.SetNX(ctx, fmt.Sprintf(bookingVerificationCooldownKey, bookingCode, email), 1, ttl).Result()
+}
+
+// GrantBookingAccess lets the session act on the order, the grants of a session expire together.
+func (r *redisRepository) GrantBookingAccess(ctx context.Context, sessionID string, orderID uuid.UUID, ttl time.Duration) error {
+	key := fmt.Sprintf(bookingAccessKey, sessionID)
+	pipe := r.RedisClient.TxPipeline()
+	pipe.SAdd(ctx, key, orderID.String())
+	pipe.Expire(ctx, key, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *redisRepository) HasBookingAccess(ctx context.Context, sessionID string, orderID uuid.UUID) (bool, error) {
+	return r.RedisClient.SIsMember(ctx, fmt.Sprintf(bookingAccessKey, sessionID), orderID.String()).Result()
+}
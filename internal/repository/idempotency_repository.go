@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"railway-go/internal/constant/model"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type IdempotencyRepository interface {
+	ClaimIdempotencyKey(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	GetIdempotencyRecord(ctx context.Context, key string) (model.IdempotencyRecord, error)
+	SaveIdempotencyRecord(ctx context.Context, key string, record model.IdempotencyRecord, ttl time.Duration) error
+	DeleteIdempotencyRecord(ctx context.Context, key string) error
+}
+
+const idempotencyKey = "idempotency:%s"
+
+// ClaimIdempotencyKey stores an in-flight record for the key unless a record already exists,
+// reporting whether the caller now owns the key.
+func (r *redisRepository) ClaimIdempotencyKey(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	recordJson, err := json.Marshal(model.IdempotencyRecord{})
+	if err != nil {
+		return false, err
+	}
+
+	return r.RedisClient.SetNX(ctx, fmt.Sprintf(idempotencyKey, key), recordJson, ttl).Result()
+}
+
+func (r *redisRepository) GetIdempotencyRecord(ctx context.Context, key string) (model.IdempotencyRecord, error) {
+	val, err := r.RedisClient.Get(ctx, fmt.Sprintf(idempotencyKey, key)).Bytes()
+	if err == redis.Nil {
+		return model.IdempotencyRecord{}, model.ErrIdempotencyKeyNotFound
+	} else if err != nil {
+		return model.IdempotencyRecord{}, err
+	}
+
+	var record model.IdempotencyRecord
+	if err := json.Unmarshal(val, &record); err != nil {
+		return model.IdempotencyRecord{}, err
+	}
+	return record, nil
+}
+
+func (r *redisRepository) SaveIdempotencyRecord(ctx context.Context, key string, record model.IdempotencyRecord, ttl time.Duration) error {
+	recordJson, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return r.RedisClient.Set(ctx, fmt.Sprintf(idempotencyKey, key), recordJson, ttl).Err()
+}
+
+func (r *redisRepository) DeleteIdempotencyRecord(ctx context.Context, key string) error {
+	return r.RedisClient.Del(ctx, fmt.Sprintf(idempotencyKey, key)).Err()
+}
@@ -12,6 +12,49 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type BoardingStatus string
+
+const (
+	BoardingStatusCheckedIn BoardingStatus = "checked_in"
+	BoardingStatusBoarded   BoardingStatus = "boarded"
+	BoardingStatusNoShow    BoardingStatus = "no_show"
+)
+
+func (e *BoardingStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = BoardingStatus(s)
+	case string:
+		*e = BoardingStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for BoardingStatus: %T", src)
+	}
+	return nil
+}
+
+type NullBoardingStatus struct {
+	BoardingStatus BoardingStatus `json:"boarding_status"`
+	Valid          bool           `json:"valid"` // Valid is true if BoardingStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullBoardingStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.BoardingStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.BoardingStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullBoardingStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.BoardingStatus), nil
+}
+
 type SeatRow string
 
 const (
@@ -62,6 +105,7 @@ const (
 	StatusReservationPending   StatusReservation = "pending"
 	StatusReservationSuccess   StatusReservation = "success"
 	StatusReservationCancelled StatusReservation = "cancelled"
+	StatusReservationRefunded  StatusReservation = "refunded"
 )
 
 func (e *StatusReservation) Scan(src interface{}) error {
@@ -148,6 +192,7 @@ const (
 	UserRoleUser           UserRole = "user"
 	UserRoleGeneralaffairs UserRole = "general affairs"
 	UserRoleAdmin          UserRole = "admin"
+	UserRoleConductor      UserRole = "conductor"
 )
 
 func (e *UserRole) Scan(src interface{}) error {
@@ -185,6 +230,49 @@ func (ns NullUserRole) Value() (driver.Value, error) {
 	return string(ns.UserRole), nil
 }
 
+type WaitlistStatus string
+
+const (
+	WaitlistStatusWaiting   WaitlistStatus = "waiting"
+	WaitlistStatusPromoted  WaitlistStatus = "promoted"
+	WaitlistStatusCancelled WaitlistStatus = "cancelled"
+)
+
+func (e *WaitlistStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WaitlistStatus(s)
+	case string:
+		*e = WaitlistStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WaitlistStatus: %T", src)
+	}
+	return nil
+}
+
+type NullWaitlistStatus struct {
+	WaitlistStatus WaitlistStatus `json:"waitlist_status"`
+	Valid          bool           `json:"valid"` // Valid is true if WaitlistStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWaitlistStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.WaitlistStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WaitlistStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWaitlistStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WaitlistStatus), nil
+}
+
 type DiscountCode struct {
 	ID              uuid.UUID        `db:"id" json:"id"`
 	Code            string           `db:"code" json:"code"`
@@ -195,18 +283,31 @@ type DiscountCode struct {
 	UpdatedAt       pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 }
 
+type Order struct {
+	ID           uuid.UUID        `db:"id" json:"id"`
+	TotalPrice   int64            `db:"total_price" json:"total_price"`
+	ExpiresAt    pgtype.Timestamp `db:"expires_at" json:"expires_at"`
+	CreatedAt    pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt    pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	BookingCode  string           `db:"booking_code" json:"booking_code"`
+	ContactEmail *string          `db:"contact_email" json:"contact_email"`
+	ContactPhone *string          `db:"contact_phone" json:"contact_phone"`
+}
+
 type Passenger struct {
-	ID        uuid.UUID        `db:"id" json:"id"`
-	Name      string           `db:"name" json:"name"`
-	IDNumber  string           `db:"id_number" json:"id_number"`
-	UserID    pgtype.UUID      `db:"user_id" json:"user_id"`
-	CreatedAt pgtype.Timestamp `db:"created_at" json:"created_at"`
-	UpdatedAt pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	ID            uuid.UUID        `db:"id" json:"id"`
+	Name          string           `db:"name" json:"name"`
+	IDNumber      string           `db:"id_number" json:"id_number"`
+	UserID        pgtype.UUID      `db:"user_id" json:"user_id"`
+	CreatedAt     pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	SessionID     *string          `db:"session_id" json:"session_id"`
+	PassengerType string           `db:"passenger_type" json:"passenger_type"`
 }
 
 type Payment struct {
 	ID              uuid.UUID        `db:"id" json:"id"`
-	ReservationID   uuid.UUID        `db:"reservation_id" json:"reservation_id"`
+	ReservationID   pgtype.UUID      `db:"reservation_id" json:"reservation_id"`
 	PaymentMethod   string           `db:"payment_method" json:"payment_method"`
 	Amount          int64            `db:"amount" json:"amount"`
 	TransactionID   string           `db:"transaction_id" json:"transaction_id"`
@@ -215,6 +316,19 @@ type Payment struct {
 	PaymentStatus   string           `db:"payment_status" json:"payment_status"`
 	CreatedAt       pgtype.Timestamp `db:"created_at" json:"created_at"`
 	UpdatedAt       pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	OrderID         uuid.UUID        `db:"order_id" json:"order_id"`
+}
+
+type Refund struct {
+	ID            uuid.UUID        `db:"id" json:"id"`
+	ReservationID uuid.UUID        `db:"reservation_id" json:"reservation_id"`
+	PaymentID     pgtype.UUID      `db:"payment_id" json:"payment_id"`
+	PaidAmount    int64            `db:"paid_amount" json:"paid_amount"`
+	FeePercent    int32            `db:"fee_percent" json:"fee_percent"`
+	Fee           int64            `db:"fee" json:"fee"`
+	Amount        int64            `db:"amount" json:"amount"`
+	Reason        *string          `db:"reason" json:"reason"`
+	CreatedAt     pgtype.Timestamp `db:"created_at" json:"created_at"`
 }
 
 type Reservation struct {
@@ -230,6 +344,10 @@ type Reservation struct {
 	ExpiresAt         pgtype.Timestamp  `db:"expires_at" json:"expires_at"`
 	CreatedAt         pgtype.Timestamp  `db:"created_at" json:"created_at"`
 	UpdatedAt         pgtype.Timestamp  `db:"updated_at" json:"updated_at"`
+	OrderID           uuid.UUID         `db:"order_id" json:"order_id"`
+	FromStop          int32             `db:"from_stop" json:"from_stop"`
+	ToStop            int32             `db:"to_stop" json:"to_stop"`
+	PassengerType     string            `db:"passenger_type" json:"passenger_type"`
 }
 
 type ReservationDiscount struct {
@@ -237,6 +355,24 @@ type ReservationDiscount struct {
 	DiscountID    uuid.UUID `db:"discount_id" json:"discount_id"`
 }
 
+type ReservationExchange struct {
+	ID                    uuid.UUID        `db:"id" json:"id"`
+	OriginalReservationID uuid.UUID        `db:"original_reservation_id" json:"original_reservation_id"`
+	NewReservationID      uuid.UUID        `db:"new_reservation_id" json:"new_reservation_id"`
+	FareDifference        int64            `db:"fare_difference" json:"fare_difference"`
+	CreatedAt             pgtype.Timestamp `db:"created_at" json:"created_at"`
+}
+
+type ReservationStatusHistory struct {
+	ID            int64                 `db:"id" json:"id"`
+	ReservationID uuid.UUID             `db:"reservation_id" json:"reservation_id"`
+	FromStatus    NullStatusReservation `db:"from_status" json:"from_status"`
+	ToStatus      StatusReservation     `db:"to_status" json:"to_status"`
+	Reason        string                `db:"reason" json:"reason"`
+	Actor         string                `db:"actor" json:"actor"`
+	CreatedAt     pgtype.Timestamp      `db:"created_at" json:"created_at"`
+}
+
 type Route struct {
 	ID                 int64            `db:"id" json:"id"`
 	SourceStation      string           `db:"source_station" json:"source_station"`
@@ -246,16 +382,24 @@ type Route struct {
 	UpdatedAt          pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 }
 
+type RouteStop struct {
+	ID               int64            `db:"id" json:"id"`
+	RouteID          int64            `db:"route_id" json:"route_id"`
+	StopOrder        int32            `db:"stop_order" json:"stop_order"`
+	StationCode      string           `db:"station_code" json:"station_code"`
+	MinutesFromStart int32            `db:"minutes_from_start" json:"minutes_from_start"`
+	CreatedAt        pgtype.Timestamp `db:"created_at" json:"created_at"`
+}
+
 type Schedule struct {
-	ID             int64            `db:"id" json:"id"`
-	TrainID        int64            `db:"train_id" json:"train_id"`
-	RouteID        int64            `db:"route_id" json:"route_id"`
-	DepartureDate  pgtype.Timestamp `db:"departure_date" json:"departure_date"`
-	ArrivalDate    pgtype.Timestamp `db:"arrival_date" json:"arrival_date"`
-	AvailableSeats int32            `db:"available_seats" json:"available_seats"`
-	Price          int64            `db:"price" json:"price"`
-	CreatedAt      pgtype.Timestamp `db:"created_at" json:"created_at"`
-	UpdatedAt      pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	ID            int64            `db:"id" json:"id"`
+	TrainID       int64            `db:"train_id" json:"train_id"`
+	RouteID       int64            `db:"route_id" json:"route_id"`
+	DepartureDate pgtype.Timestamp `db:"departure_date" json:"departure_date"`
+	ArrivalDate   pgtype.Timestamp `db:"arrival_date" json:"arrival_date"`
+	Price         int64            `db:"price" json:"price"`
+	CreatedAt     pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 }
 
 type Seat struct {
@@ -268,6 +412,15 @@ type Seat struct {
 	UpdatedAt   pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 }
 
+type SeatLock struct {
+	ScheduleID int64            `db:"schedule_id" json:"schedule_id"`
+	WagonID    int64            `db:"wagon_id" json:"wagon_id"`
+	SeatID     int64            `db:"seat_id" json:"seat_id"`
+	Owner      string           `db:"owner" json:"owner"`
+	ExpiresAt  pgtype.Timestamp `db:"expires_at" json:"expires_at"`
+	CreatedAt  pgtype.Timestamp `db:"created_at" json:"created_at"`
+}
+
 type Station struct {
 	ID          int64            `db:"id" json:"id"`
 	Code        string           `db:"code" json:"code"`
@@ -276,6 +429,17 @@ type Station struct {
 	UpdatedAt   pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 }
 
+type TicketBoarding struct {
+	ID            int64            `db:"id" json:"id"`
+	ReservationID uuid.UUID        `db:"reservation_id" json:"reservation_id"`
+	ScheduleID    int64            `db:"schedule_id" json:"schedule_id"`
+	ConductorID   pgtype.UUID      `db:"conductor_id" json:"conductor_id"`
+	BoardedAt     pgtype.Timestamp `db:"boarded_at" json:"boarded_at"`
+	Status        BoardingStatus   `db:"status" json:"status"`
+	CheckedInAt   pgtype.Timestamp `db:"checked_in_at" json:"checked_in_at"`
+	UpdatedAt     pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+}
+
 type Train struct {
 	ID        int64            `db:"id" json:"id"`
 	Name      string           `db:"name" json:"name"`
@@ -304,3 +468,16 @@ type Wagon struct {
 	CreatedAt   pgtype.Timestamp `db:"created_at" json:"created_at"`
 	UpdatedAt   pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 }
+
+type WaitlistEntry struct {
+	ID            uuid.UUID        `db:"id" json:"id"`
+	PassengerID   uuid.UUID        `db:"passenger_id" json:"passenger_id"`
+	ScheduleID    int64            `db:"schedule_id" json:"schedule_id"`
+	ClassType     TipeClass        `db:"class_type" json:"class_type"`
+	FromStop      int32            `db:"from_stop" json:"from_stop"`
+	ToStop        int32            `db:"to_stop" json:"to_stop"`
+	Status        WaitlistStatus   `db:"status" json:"status"`
+	ReservationID pgtype.UUID      `db:"reservation_id" json:"reservation_id"`
+	CreatedAt     pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+}
@@ -13,18 +13,20 @@ import (
 )
 
 const createPassenger = `-- name: CreatePassenger :one
-INSERT INTO passengers (id, name, id_number, user_id) 
+INSERT INTO passengers (id, name, id_number, user_id, session_id, passenger_type) 
 VALUES (
-  $1, $2, $3, $4
+  $1, $2, $3, $4, $5, $6
 )
-RETURNING id, name, id_number, user_id, created_at, updated_at
+RETURNING id, name, id_number, user_id, created_at, updated_at, session_id, passenger_type
 `
 
 type CreatePassengerParams struct {
-	ID       uuid.UUID   `db:"id" json:"id"`
-	Name     string      `db:"name" json:"name"`
-	IDNumber string      `db:"id_number" json:"id_number"`
-	UserID   pgtype.UUID `db:"user_id" json:"user_id"`
+	ID            uuid.UUID   `db:"id" json:"id"`
+	Name          string      `db:"name" json:"name"`
+	IDNumber      string      `db:"id_number" json:"id_number"`
+	UserID        pgtype.UUID `db:"user_id" json:"user_id"`
+	SessionID     *string     `db:"session_id" json:"session_id"`
+	PassengerType string      `db:"passenger_type" json:"passenger_type"`
 }
 
 func (q *Queries) CreatePassenger(ctx context.Context, arg CreatePassengerParams) (Passenger, error) {
@@ -33,6 +35,8 @@ func (q *Queries) CreatePassenger(ctx context.Context, arg CreatePassengerParams
 		arg.Name,
 		arg.IDNumber,
 		arg.UserID,
+		arg.SessionID,
+		arg.PassengerType,
 	)
 	var i Passenger
 	err := row.Scan(
@@ -42,6 +46,8 @@ func (q *Queries) CreatePassenger(ctx context.Context, arg CreatePassengerParams
 		&i.UserID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SessionID,
+		&i.PassengerType,
 	)
 	return i, err
 }
@@ -57,7 +63,7 @@ func (q *Queries) DeletePassenger(ctx context.Context, id uuid.UUID) error {
 }
 
 const getPassenger = `-- name: GetPassenger :one
-SELECT id, name, id_number, user_id, created_at, updated_at FROM  passengers
+SELECT id, name, id_number, user_id, created_at, updated_at, session_id, passenger_type FROM  passengers
 WHERE id = $1 LIMIT 1
 `
 
@@ -71,15 +77,21 @@ func (q *Queries) GetPassenger(ctx context.Context, id uuid.UUID) (Passenger, er
 		&i.UserID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SessionID,
+		&i.PassengerType,
 	)
 	return i, err
 }
 
 const getPassengerByUser = `-- name: GetPassengerByUser :one
-SELECT id, name, id_number, user_id, created_at, updated_at FROM passengers
+SELECT id, name, id_number, user_id, created_at, updated_at, session_id, passenger_type FROM passengers
 WHERE user_id = $1
+ORDER BY created_at, id
+LIMIT 1
 `
 
+// the oldest passenger of the user is the default one, a user owns several after creating
+// more or merging guest bookings
 func (q *Queries) GetPassengerByUser(ctx context.Context, userID pgtype.UUID) (Passenger, error) {
 	row := q.db.QueryRow(ctx, getPassengerByUser, userID)
 	var i Passenger
@@ -90,12 +102,14 @@ func (q *Queries) GetPassengerByUser(ctx context.Context, userID pgtype.UUID) (P
 		&i.UserID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SessionID,
+		&i.PassengerType,
 	)
 	return i, err
 }
 
 const listPassengers = `-- name: ListPassengers :many
-SELECT id, name, id_number, user_id, created_at, updated_at FROM passengers
+SELECT id, name, id_number, user_id, created_at, updated_at, session_id, passenger_type FROM passengers
 ORDER BY name
 `
 
@@ -115,6 +129,8 @@ func (q *Queries) ListPassengers(ctx context.Context) ([]Passenger, error) {
 			&i.UserID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.SessionID,
+			&i.PassengerType,
 		); err != nil {
 			return nil, err
 		}
@@ -126,19 +142,88 @@ func (q *Queries) ListPassengers(ctx context.Context) ([]Passenger, error) {
 	return items, nil
 }
 
+const listPassengersByUser = `-- name: ListPassengersByUser :many
+SELECT id, name, id_number, user_id, created_at, updated_at, session_id, passenger_type FROM passengers
+WHERE user_id = $1
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListPassengersByUser(ctx context.Context, userID pgtype.UUID) ([]Passenger, error) {
+	rows, err := q.db.Query(ctx, listPassengersByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Passenger{}
+	for rows.Next() {
+		var i Passenger
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.IDNumber,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.SessionID,
+			&i.PassengerType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const mergeGuestPassengers = `-- name: MergeGuestPassengers :many
+UPDATE passengers
+SET user_id = $1, session_id = NULL, updated_at = NOW()
+WHERE session_id = $2 AND user_id IS NULL
+RETURNING id
+`
+
+type MergeGuestPassengersParams struct {
+	UserID    pgtype.UUID `db:"user_id" json:"user_id"`
+	SessionID *string     `db:"session_id" json:"session_id"`
+}
+
+func (q *Queries) MergeGuestPassengers(ctx context.Context, arg MergeGuestPassengersParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, mergeGuestPassengers, arg.UserID, arg.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updatePassenger = `-- name: UpdatePassenger :exec
 UPDATE passengers
   set name = $2,
   id_number = $3,
-  user_id = $4
+  user_id = $4,
+  passenger_type = $5
 WHERE id = $1
 `
 
 type UpdatePassengerParams struct {
-	ID       uuid.UUID   `db:"id" json:"id"`
-	Name     string      `db:"name" json:"name"`
-	IDNumber string      `db:"id_number" json:"id_number"`
-	UserID   pgtype.UUID `db:"user_id" json:"user_id"`
+	ID            uuid.UUID   `db:"id" json:"id"`
+	Name          string      `db:"name" json:"name"`
+	IDNumber      string      `db:"id_number" json:"id_number"`
+	UserID        pgtype.UUID `db:"user_id" json:"user_id"`
+	PassengerType string      `db:"passenger_type" json:"passenger_type"`
 }
 
 func (q *Queries) UpdatePassenger(ctx context.Context, arg UpdatePassengerParams) error {
@@ -147,6 +232,7 @@ func (q *Queries) UpdatePassenger(ctx context.Context, arg UpdatePassengerParams
 		arg.Name,
 		arg.IDNumber,
 		arg.UserID,
+		arg.PassengerType,
 	)
 	return err
 }
@@ -25,20 +25,21 @@ func (q *Queries) CompletePayment(ctx context.Context, id uuid.UUID) error {
 
 const createPayment = `-- name: CreatePayment :exec
 INSERT INTO payments (
-    reservation_id, payment_method, amount, transaction_id, payment_date, gateway_response, payment_status
+    reservation_id, payment_method, amount, transaction_id, payment_date, gateway_response, payment_status, order_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, $8
 )
 `
 
 type CreatePaymentParams struct {
-	ReservationID   uuid.UUID        `db:"reservation_id" json:"reservation_id"`
+	ReservationID   pgtype.UUID      `db:"reservation_id" json:"reservation_id"`
 	PaymentMethod   string           `db:"payment_method" json:"payment_method"`
 	Amount          int64            `db:"amount" json:"amount"`
 	TransactionID   string           `db:"transaction_id" json:"transaction_id"`
 	PaymentDate     pgtype.Timestamp `db:"payment_date" json:"payment_date"`
 	GatewayResponse *string          `db:"gateway_response" json:"gateway_response"`
 	PaymentStatus   string           `db:"payment_status" json:"payment_status"`
+	OrderID         uuid.UUID        `db:"order_id" json:"order_id"`
 }
 
 func (q *Queries) CreatePayment(ctx context.Context, arg CreatePaymentParams) error {
@@ -50,6 +51,7 @@ func (q *Queries) CreatePayment(ctx context.Context, arg CreatePaymentParams) er
 		arg.PaymentDate,
 		arg.GatewayResponse,
 		arg.PaymentStatus,
+		arg.OrderID,
 	)
 	return err
 }
@@ -77,18 +79,18 @@ func (q *Queries) FailPayment(ctx context.Context, id uuid.UUID) error {
 
 const getExpiredPayments = `-- name: GetExpiredPayments :many
 SELECT reservation_id FROM payments
-WHERE payment_status = 'pending' AND creaate_at < NOW() - INTERVAL '15 minutes'
+WHERE payment_status = 'pending' AND created_at < NOW() - INTERVAL '15 minutes'
 `
 
-func (q *Queries) GetExpiredPayments(ctx context.Context) ([]uuid.UUID, error) {
+func (q *Queries) GetExpiredPayments(ctx context.Context) ([]pgtype.UUID, error) {
 	rows, err := q.db.Query(ctx, getExpiredPayments)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []uuid.UUID{}
+	items := []pgtype.UUID{}
 	for rows.Next() {
-		var reservation_id uuid.UUID
+		var reservation_id pgtype.UUID
 		if err := rows.Scan(&reservation_id); err != nil {
 			return nil, err
 		}
@@ -101,7 +103,7 @@ func (q *Queries) GetExpiredPayments(ctx context.Context) ([]uuid.UUID, error) {
 }
 
 const getPayment = `-- name: GetPayment :one
-SELECT id, reservation_id, payment_method, amount, transaction_id, payment_date, gateway_response, payment_status, created_at, updated_at FROM  payments
+SELECT id, reservation_id, payment_method, amount, transaction_id, payment_date, gateway_response, payment_status, created_at, updated_at, order_id FROM  payments
 WHERE id = $1 LIMIT 1
 `
 
@@ -119,12 +121,40 @@ func (q *Queries) GetPayment(ctx context.Context, id uuid.UUID) (Payment, error)
 		&i.PaymentStatus,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OrderID,
+	)
+	return i, err
+}
+
+const getReservationPayment = `-- name: GetReservationPayment :one
+SELECT py.id, py.reservation_id, py.payment_method, py.amount, py.transaction_id, py.payment_date, py.gateway_response, py.payment_status, py.created_at, py.updated_at, py.order_id FROM payments py
+JOIN reservations r ON py.reservation_id = r.id OR (py.reservation_id IS NULL AND py.order_id = r.order_id)
+WHERE r.id = $1 AND py.payment_status = 'success'
+ORDER BY py.payment_date DESC
+LIMIT 1
+`
+
+func (q *Queries) GetReservationPayment(ctx context.Context, id uuid.UUID) (Payment, error) {
+	row := q.db.QueryRow(ctx, getReservationPayment, id)
+	var i Payment
+	err := row.Scan(
+		&i.ID,
+		&i.ReservationID,
+		&i.PaymentMethod,
+		&i.Amount,
+		&i.TransactionID,
+		&i.PaymentDate,
+		&i.GatewayResponse,
+		&i.PaymentStatus,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrderID,
 	)
 	return i, err
 }
 
 const listPayments = `-- name: ListPayments :many
-SELECT id, reservation_id, payment_method, amount, transaction_id, payment_date, gateway_response, payment_status, created_at, updated_at FROM payments
+SELECT id, reservation_id, payment_method, amount, transaction_id, payment_date, gateway_response, payment_status, created_at, updated_at, order_id FROM payments
 ORDER BY id
 `
 
@@ -148,6 +178,7 @@ func (q *Queries) ListPayments(ctx context.Context) ([]Payment, error) {
 			&i.PaymentStatus,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.OrderID,
 		); err != nil {
 			return nil, err
 		}
@@ -173,7 +204,7 @@ WHERE id = $1
 
 type UpdatePaymentParams struct {
 	ID              uuid.UUID        `db:"id" json:"id"`
-	ReservationID   uuid.UUID        `db:"reservation_id" json:"reservation_id"`
+	ReservationID   pgtype.UUID      `db:"reservation_id" json:"reservation_id"`
 	PaymentMethod   string           `db:"payment_method" json:"payment_method"`
 	Amount          int64            `db:"amount" json:"amount"`
 	TransactionID   string           `db:"transaction_id" json:"transaction_id"`
@@ -12,104 +12,147 @@ import (
 )
 
 type Querier interface {
+	AcquireSeatLock(ctx context.Context, arg AcquireSeatLockParams) (int64, error)
 	ApplyDiscountToReservation(ctx context.Context, arg ApplyDiscountToReservationParams) error
-	CancelReservation(ctx context.Context, id uuid.UUID) error
+	BoardTicket(ctx context.Context, arg BoardTicketParams) (TicketBoarding, error)
+	CancelWaitlistEntry(ctx context.Context, id uuid.UUID) error
+	CheckInTicket(ctx context.Context, arg CheckInTicketParams) (TicketBoarding, error)
 	CheckSeatAvailability(ctx context.Context, arg CheckSeatAvailabilityParams) (int64, error)
 	CompletePayment(ctx context.Context, id uuid.UUID) error
-	// -- name: HoldSeat :exec
-	// INSERT INTO seat_holds (passenger_id, schedule_id, wagon_id, seat_id, expires_at)
-	// VALUES ($1, $2, $3, $4, NOW() + INTERVAL '15 minutes')
-	// RETURNING *;
-	// -- name: CreateReservationFromHold :one
-	// WITH deleted_hold AS (
-	//     DELETE FROM seat_holds
-	//     WHERE seat_holds.passenger_id = $1
-	//     AND seat_holds.schedule_id = $2
-	//     AND seat_holds.wagon_id = $3
-	//     AND seat_holds.seat_id = $4
-	//     RETURNING passenger_id, schedule_id, wagon_id, seat_id
-	// )
-	// INSERT INTO reservations (
-	//     id, passenger_id, schedule_id, wagon_id, seat_id, booking_date, reservation_status, discount_id, price, expires_at
-	// -- )
-	// SELECT
-	//     uuid_generate_v4(), deleted_hold.passenger_id, deleted_hold.schedule_id, deleted_hold.wagon_id, deleted_hold.seat_id,
-	//     NOW(), 'pending', $5, $6, NOW() + INTERVAL '15 minutes'
-	// FROM deleted_hold
-	// RETURNING *;
-	ConfirmReservation(ctx context.Context, id uuid.UUID) error
-	CountReservations(ctx context.Context) (int64, error)
+	CountActiveRouteReservations(ctx context.Context, routeID int64) (int64, error)
+	CountReservations(ctx context.Context, arg CountReservationsParams) (int64, error)
 	CountUserByEmail(ctx context.Context, email string) (int64, error)
+	CountUserReservations(ctx context.Context, arg CountUserReservationsParams) (int64, error)
 	CreateDiscountCode(ctx context.Context, arg CreateDiscountCodeParams) (DiscountCode, error)
+	CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error)
 	CreatePassenger(ctx context.Context, arg CreatePassengerParams) (Passenger, error)
 	CreatePayment(ctx context.Context, arg CreatePaymentParams) error
+	CreateRefund(ctx context.Context, arg CreateRefundParams) (Refund, error)
 	CreateReservation(ctx context.Context, arg CreateReservationParams) (Reservation, error)
+	CreateReservationExchange(ctx context.Context, arg CreateReservationExchangeParams) (ReservationExchange, error)
+	CreateReservationStatusHistory(ctx context.Context, arg CreateReservationStatusHistoryParams) error
 	CreateRoute(ctx context.Context, arg CreateRouteParams) (Route, error)
+	CreateRouteStop(ctx context.Context, arg CreateRouteStopParams) (RouteStop, error)
 	CreateSchedule(ctx context.Context, arg CreateScheduleParams) (Schedule, error)
 	CreateSeat(ctx context.Context, arg CreateSeatParams) (Seat, error)
 	CreateStation(ctx context.Context, arg CreateStationParams) (Station, error)
 	CreateTrain(ctx context.Context, arg CreateTrainParams) (Train, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) error
 	CreateWagon(ctx context.Context, arg CreateWagonParams) (Wagon, error)
-	DecreaseWagonSeat(ctx context.Context, id int64) error
+	CreateWaitlistEntry(ctx context.Context, arg CreateWaitlistEntryParams) (WaitlistEntry, error)
+	DeleteExpiredSeatLocks(ctx context.Context) (int64, error)
 	DeletePassenger(ctx context.Context, id uuid.UUID) error
 	DeletePayment(ctx context.Context, id uuid.UUID) error
 	DeleteReservation(ctx context.Context, id uuid.UUID) error
 	DeleteRoute(ctx context.Context, id int64) error
+	DeleteRouteStops(ctx context.Context, routeID int64) error
 	DeleteSchedule(ctx context.Context, id int64) error
 	DeleteSeat(ctx context.Context, id int64) error
 	DeleteStation(ctx context.Context, id int64) error
 	DeleteTrain(ctx context.Context, id int64) error
 	DeleteUser(ctx context.Context, id uuid.UUID) error
 	DeleteWagon(ctx context.Context, id int64) error
-	// -- name: CleanupExpiredHolds :exec
-	// DELETE FROM seat_holds WHERE expires_at < NOW();
-	// -- name: ExpireSeatHolds :exec
-	// DELETE FROM seat_holds
-	// WHERE expires_at < NOW();
-	ExpireUndpaidReservations(ctx context.Context) error
+	ExpireSettledOrder(ctx context.Context, id uuid.UUID) error
 	FailPayment(ctx context.Context, id uuid.UUID) error
 	GetDiscountByCode(ctx context.Context, code string) (DiscountCode, error)
 	GetDiscountByID(ctx context.Context, id uuid.UUID) (DiscountCode, error)
 	GetDiscountsForReservation(ctx context.Context, discountID uuid.UUID) ([]GetDiscountsForReservationRow, error)
-	GetExpiredPayments(ctx context.Context) ([]uuid.UUID, error)
+	GetExpiredPayments(ctx context.Context) ([]pgtype.UUID, error)
 	GetFullReservation(ctx context.Context, id uuid.UUID) (GetFullReservationRow, error)
+	GetOrder(ctx context.Context, id uuid.UUID) (Order, error)
+	GetOrderByCode(ctx context.Context, bookingCode string) (Order, error)
 	GetPassenger(ctx context.Context, id uuid.UUID) (Passenger, error)
+	// the oldest passenger of the user is the default one, a user owns several after creating
+	// more or merging guest bookings
 	GetPassengerByUser(ctx context.Context, userID pgtype.UUID) (Passenger, error)
 	GetPayment(ctx context.Context, id uuid.UUID) (Payment, error)
+	GetRefundByReservation(ctx context.Context, reservationID uuid.UUID) (Refund, error)
 	GetReservation(ctx context.Context, id uuid.UUID) (Reservation, error)
+	GetReservationPayment(ctx context.Context, id uuid.UUID) (Payment, error)
 	GetRoute(ctx context.Context, id int64) (Route, error)
 	GetSchedule(ctx context.Context, id int64) (Schedule, error)
 	GetSeat(ctx context.Context, id int64) (Seat, error)
 	GetStation(ctx context.Context, id int64) (Station, error)
 	GetStationByCode(ctx context.Context, code string) (Station, error)
 	GetStationByName(ctx context.Context, stationName string) (Station, error)
+	GetTicketBoarding(ctx context.Context, reservationID uuid.UUID) (TicketBoarding, error)
 	GetTrain(ctx context.Context, id int64) (Train, error)
 	GetUser(ctx context.Context, id uuid.UUID) (User, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetWagon(ctx context.Context, id int64) (Wagon, error)
+	GetWaitlistEntry(ctx context.Context, id uuid.UUID) (WaitlistEntry, error)
+	GetWaitlistPosition(ctx context.Context, id uuid.UUID) (int64, error)
+	ListActiveSeatLocks(ctx context.Context, scheduleID int64) ([]ListActiveSeatLocksRow, error)
+	ListDepartingSchedules(ctx context.Context, arg ListDepartingSchedulesParams) ([]ListDepartingSchedulesRow, error)
+	// -- name: CleanupExpiredHolds :exec
+	// DELETE FROM seat_holds WHERE expires_at < NOW();
+	// -- name: ExpireSeatHolds :exec
+	// DELETE FROM seat_holds
+	// WHERE expires_at < NOW();
+	ListExpiredReservations(ctx context.Context) ([]Reservation, error)
+	ListFullReservationsByOrder(ctx context.Context, orderID uuid.UUID) ([]ListFullReservationsByOrderRow, error)
+	ListOrderReservations(ctx context.Context, orderID uuid.UUID) ([]Reservation, error)
 	ListPassengers(ctx context.Context) ([]Passenger, error)
+	ListPassengersByUser(ctx context.Context, userID pgtype.UUID) ([]Passenger, error)
 	ListPayments(ctx context.Context) ([]Payment, error)
+	ListReservationStatusHistory(ctx context.Context, reservationID uuid.UUID) ([]ReservationStatusHistory, error)
 	ListReservations(ctx context.Context, arg ListReservationsParams) ([]ListReservationsRow, error)
 	ListRoute(ctx context.Context) ([]Route, error)
+	ListRouteStops(ctx context.Context, routeID int64) ([]RouteStop, error)
+	ListRouteStopsByRoutes(ctx context.Context, routeIds []int64) ([]RouteStop, error)
+	ListScheduleAvailability(ctx context.Context, scheduleIds []int64) ([]ListScheduleAvailabilityRow, error)
+	// ListScheduleBookedLegs groups the bookable seats of the schedules by class and by the legs their
+	// pending and paid reservations occupy, bit n of booked_legs is leg n of the route.
+	ListScheduleBookedLegs(ctx context.Context, scheduleIds []int64) ([]ListScheduleBookedLegsRow, error)
+	ListScheduleSeats(ctx context.Context, arg ListScheduleSeatsParams) ([]ListScheduleSeatsRow, error)
 	ListSchedules(ctx context.Context) ([]Schedule, error)
 	ListSeats(ctx context.Context, wagonID *int64) ([]Seat, error)
 	ListStations(ctx context.Context) ([]Station, error)
 	ListTrains(ctx context.Context) ([]Train, error)
+	ListUserReservations(ctx context.Context, arg ListUserReservationsParams) ([]ListUserReservationsRow, error)
 	ListUsers(ctx context.Context) ([]User, error)
 	ListWagons(ctx context.Context, trainID int64) ([]Wagon, error)
+	ListWaitingEntries(ctx context.Context, scheduleID int64) ([]WaitlistEntry, error)
+	MarkNoShows(ctx context.Context, arg MarkNoShowsParams) ([]MarkNoShowsRow, error)
+	MergeGuestPassengers(ctx context.Context, arg MergeGuestPassengersParams) ([]uuid.UUID, error)
+	NoShowReport(ctx context.Context, arg NoShowReportParams) ([]NoShowReportRow, error)
+	PromoteWaitlistEntry(ctx context.Context, arg PromoteWaitlistEntryParams) error
 	ReduceDiscountUsage(ctx context.Context, id uuid.UUID) error
+	RefreshSeatLock(ctx context.Context, arg RefreshSeatLockParams) (int64, error)
+	ReleaseSeatLock(ctx context.Context, arg ReleaseSeatLockParams) (int64, error)
 	SearchSchedules(ctx context.Context, arg SearchSchedulesParams) ([]SearchSchedulesRow, error)
 	UpdateDiscountCode(ctx context.Context, arg UpdateDiscountCodeParams) error
+	UpdateOrderContact(ctx context.Context, arg UpdateOrderContactParams) (Order, error)
 	UpdatePassenger(ctx context.Context, arg UpdatePassengerParams) error
 	UpdatePayment(ctx context.Context, arg UpdatePaymentParams) error
 	UpdateReservation(ctx context.Context, arg UpdateReservationParams) error
+	// -- name: HoldSeat :exec
+	// INSERT INTO seat_holds (passenger_id, schedule_id, wagon_id, seat_id, expires_at)
+	// VALUES ($1, $2, $3, $4, NOW() + INTERVAL '15 minutes')
+	// RETURNING *;
+	// -- name: CreateReservationFromHold :one
+	// WITH deleted_hold AS (
+	//     DELETE FROM seat_holds
+	//     WHERE seat_holds.passenger_id = $1
+	//     AND seat_holds.schedule_id = $2
+	//     AND seat_holds.wagon_id = $3
+	//     AND seat_holds.seat_id = $4
+	//     RETURNING passenger_id, schedule_id, wagon_id, seat_id
+	// )
+	// INSERT INTO reservations (
+	//     id, passenger_id, schedule_id, wagon_id, seat_id, booking_date, reservation_status, discount_id, price, expires_at
+	// -- )
+	// SELECT
+	//     uuid_generate_v4(), deleted_hold.passenger_id, deleted_hold.schedule_id, deleted_hold.wagon_id, deleted_hold.seat_id,
+	//     NOW(), 'pending', $5, $6, NOW() + INTERVAL '15 minutes'
+	// FROM deleted_hold
+	// RETURNING *;
+	UpdateReservationStatus(ctx context.Context, arg UpdateReservationStatusParams) (int64, error)
 	UpdateRoute(ctx context.Context, arg UpdateRouteParams) error
 	UpdateSchedule(ctx context.Context, arg UpdateScheduleParams) error
 	UpdateSeat(ctx context.Context, arg UpdateSeatParams) error
 	UpdateStation(ctx context.Context, arg UpdateStationParams) error
 	UpdateTrain(ctx context.Context, arg UpdateTrainParams) error
-	UpdateTrainCapacity(ctx context.Context, id int64) error
 	UpdateUser(ctx context.Context, arg UpdateUserParams) error
 	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error
 	UpdateWagon(ctx context.Context, arg UpdateWagonParams) error
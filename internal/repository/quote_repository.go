@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"railway-go/internal/constant/model"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type QuoteRepository interface {
+	SaveQuote(ctx context.Context, quote model.Quote, sessionID string, ttl time.Duration) error
+	TakeQuote(ctx context.Context, id string) (model.Quote, string, error)
+}
+
+const quoteKey = "quote:%s"
+
+// storedQuote keeps the session a quote was made for next to it, only that session may book with it.
+type storedQuote struct {
+	Quote     model.Quote `json:"quote"`
+	SessionID string      `json:"session_id"`
+}
+
+func (r *redisRepository) SaveQuote(ctx context.Context, quote model.Quote, sessionID string, ttl time.Duration) error {
+	quoteJson, err := json.Marshal(storedQuote{Quote: quote, SessionID: sessionID})
+	if err != nil {
+		return err
+	}
+
+	return r.RedisClient.Set(ctx, fmt.Sprintf(quoteKey, quote.ID), quoteJson, ttl).Err()
+}
+
+// TakeQuote removes the quote and returns it with the session it was made for, a quote is
+// taken once even by concurrent callers.
+func (r *redisRepository) TakeQuote(ctx context.Context, id string) (model.Quote, string, error) {
+	val, err := r.RedisClient.GetDel(ctx, fmt.Sprintf(quoteKey, id)).Bytes()
+	if err == redis.Nil {
+		return model.Quote{}, "", model.ErrQuoteNotFound
+	} else if err != nil {
+		return model.Quote{}, "", err
+	}
+
+	var stored storedQuote
+	if err := json.Unmarshal(val, &stored); err != nil {
+		return model.Quote{}, "", err
+	}
+	return stored.Quote, stored.SessionID, nil
+}
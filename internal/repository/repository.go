@@ -13,7 +13,12 @@ type Store interface {
 	Querier
 	SessionRepository
 	ReservationRepository
+	IdempotencyRepository
+	BookingAccessRepository
+	QuoteRepository
+	SeatLocker
 	BeginTransaction(ctx context.Context) (Transaction, error)
+	BeginSnapshot(ctx context.Context) (Transaction, error)
 }
 
 type Transaction interface {
@@ -26,6 +31,7 @@ type SQLStore struct {
 	connPool *pgxpool.Pool
 	*Queries
 	*redisRepository
+	SeatLocker
 }
 
 type SQLTransaction struct {
@@ -41,12 +47,13 @@ func NewRedis(redis *redis.Client) *redisRepository {
 	return &redisRepository{RedisClient: redis}
 }
 
-// NewStore creates a new store
-func NewStore(connPool *pgxpool.Pool, redis *redis.Client) Store {
+// NewStore creates a new store, seat locks are kept by the given locker
+func NewStore(connPool *pgxpool.Pool, redis *redis.Client, locker SeatLocker) Store {
 	return &SQLStore{
 		connPool:        connPool,
 		Queries:         New(connPool),
 		redisRepository: NewRedis(redis),
+		SeatLocker:      locker,
 	}
 }
 
@@ -63,6 +70,19 @@ func (store *SQLStore) BeginTransaction(ctx context.Context) (Transaction, error
 	}, nil
 }
 
+// BeginSnapshot starts a read only transaction in which every query sees the same snapshot
+func (store *SQLStore) BeginSnapshot(ctx context.Context) (Transaction, error) {
+	tx, err := store.connPool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLTransaction{
+		TX:      tx,
+		Queries: New(tx),
+	}, nil
+}
+
 func (t *SQLTransaction) Commit(ctx context.Context) error {
 	return t.TX.Commit(ctx)
 }
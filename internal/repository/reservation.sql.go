@@ -12,82 +12,104 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const cancelReservation = `-- name: CancelReservation :exec
-UPDATE reservations
-SET reservation_status = 'cancelled', updated_at = NOW()
-WHERE id = $1 AND reservation_status = 'pending'
-`
-
-func (q *Queries) CancelReservation(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, cancelReservation, id)
-	return err
-}
-
 const checkSeatAvailability = `-- name: CheckSeatAvailability :one
 SELECT COUNT(*) FROM reservations 
 WHERE schedule_id = $1 AND wagon_id = $2 AND seat_id = $3
   AND reservation_status IN ('pending', 'success')
+  AND int4range(from_stop, to_stop) && int4range($4::int, $5::int)
 `
 
 type CheckSeatAvailabilityParams struct {
 	ScheduleID int64 `db:"schedule_id" json:"schedule_id"`
 	WagonID    int64 `db:"wagon_id" json:"wagon_id"`
 	SeatID     int64 `db:"seat_id" json:"seat_id"`
+	FromStop   int32 `db:"from_stop" json:"from_stop"`
+	ToStop     int32 `db:"to_stop" json:"to_stop"`
 }
 
 func (q *Queries) CheckSeatAvailability(ctx context.Context, arg CheckSeatAvailabilityParams) (int64, error) {
-	row := q.db.QueryRow(ctx, checkSeatAvailability, arg.ScheduleID, arg.WagonID, arg.SeatID)
+	row := q.db.QueryRow(ctx, checkSeatAvailability,
+		arg.ScheduleID,
+		arg.WagonID,
+		arg.SeatID,
+		arg.FromStop,
+		arg.ToStop,
+	)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
 
-const confirmReservation = `-- name: ConfirmReservation :exec
-
-
-UPDATE reservations
-SET reservation_status = 'success', updated_at = NOW()
-WHERE id = $1 AND reservation_status = 'pending'
+const countReservations = `-- name: CountReservations :one
+SELECT COUNT(*) FROM reservations r
+JOIN orders o ON r.order_id = o.id
+LEFT JOIN passengers p ON r.passenger_id = p.id
+LEFT JOIN users u ON p.user_id = u.id
+LEFT JOIN schedules s ON r.schedule_id = s.id
+LEFT JOIN discount_codes d ON r.discount_id = d.id
+WHERE ($1::timestamp IS NULL OR s.departure_date >= $1::timestamp)
+  AND ($2::timestamp IS NULL OR s.departure_date < $2::timestamp)
+  AND ($3::bigint IS NULL OR s.route_id = $3::bigint)
+  AND ($4::bigint IS NULL OR s.train_id = $4::bigint)
+  AND ($5::text IS NULL OR r.reservation_status::text = $5::text)
+  AND ($6::text IS NULL OR p.name ILIKE '%' || regexp_replace($6::text, '([\\%_])', '\\\1', 'g') || '%' OR p.id_number = $6::text)
+  AND ($7::text IS NULL OR u.email ILIKE '%' || regexp_replace($7::text, '([\\%_])', '\\\1', 'g') || '%')
+  AND ($8::text IS NULL OR UPPER(d.code) = UPPER($8::text))
 `
 
-// -- name: HoldSeat :exec
-// INSERT INTO seat_holds (passenger_id, schedule_id, wagon_id, seat_id, expires_at)
-// VALUES ($1, $2, $3, $4, NOW() + INTERVAL '15 minutes')
-// RETURNING *;
-// -- name: CreateReservationFromHold :one
-// WITH deleted_hold AS (
-//
-//	DELETE FROM seat_holds
-//	WHERE seat_holds.passenger_id = $1
-//	AND seat_holds.schedule_id = $2
-//	AND seat_holds.wagon_id = $3
-//	AND seat_holds.seat_id = $4
-//	RETURNING passenger_id, schedule_id, wagon_id, seat_id
-//
-// )
-// INSERT INTO reservations (
-//
-//	id, passenger_id, schedule_id, wagon_id, seat_id, booking_date, reservation_status, discount_id, price, expires_at
-//
-// -- )
-// SELECT
-//
-//	uuid_generate_v4(), deleted_hold.passenger_id, deleted_hold.schedule_id, deleted_hold.wagon_id, deleted_hold.seat_id,
-//	NOW(), 'pending', $5, $6, NOW() + INTERVAL '15 minutes'
-//
-// FROM deleted_hold
-// RETURNING *;
-func (q *Queries) ConfirmReservation(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, confirmReservation, id)
-	return err
+type CountReservationsParams struct {
+	DepartureFrom pgtype.Timestamp `db:"departure_from" json:"departure_from"`
+	DepartureTo   pgtype.Timestamp `db:"departure_to" json:"departure_to"`
+	RouteID       *int64           `db:"route_id" json:"route_id"`
+	TrainID       *int64           `db:"train_id" json:"train_id"`
+	Status        *string          `db:"status" json:"status"`
+	Passenger     *string          `db:"passenger" json:"passenger"`
+	Email         *string          `db:"email" json:"email"`
+	DiscountCode  *string          `db:"discount_code" json:"discount_code"`
 }
 
-const countReservations = `-- name: CountReservations :one
-select count(*) from reservations
+func (q *Queries) CountReservations(ctx context.Context, arg CountReservationsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countReservations,
+		arg.DepartureFrom,
+		arg.DepartureTo,
+		arg.RouteID,
+		arg.TrainID,
+		arg.Status,
+		arg.Passenger,
+		arg.Email,
+		arg.DiscountCode,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUserReservations = `-- name: CountUserReservations :one
+SELECT COUNT(*) FROM reservations r
+JOIN passengers p ON r.passenger_id = p.id
+JOIN schedules s ON r.schedule_id = s.id
+WHERE (p.user_id = $1 OR p.session_id = $2)
+  AND (cardinality($3::text[]) = 0 OR r.reservation_status::text = ANY($3::text[]))
+  AND ($4::timestamp IS NULL OR s.departure_date >= $4::timestamp)
+  AND ($5::timestamp IS NULL OR s.departure_date < $5::timestamp)
 `
 
-func (q *Queries) CountReservations(ctx context.Context) (int64, error) {
-	row := q.db.QueryRow(ctx, countReservations)
+type CountUserReservationsParams struct {
+	UserID        pgtype.UUID      `db:"user_id" json:"user_id"`
+	SessionID     *string          `db:"session_id" json:"session_id"`
+	Statuses      []string         `db:"statuses" json:"statuses"`
+	DepartureFrom pgtype.Timestamp `db:"departure_from" json:"departure_from"`
+	DepartureTo   pgtype.Timestamp `db:"departure_to" json:"departure_to"`
+}
+
+func (q *Queries) CountUserReservations(ctx context.Context, arg CountUserReservationsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUserReservations,
+		arg.UserID,
+		arg.SessionID,
+		arg.Statuses,
+		arg.DepartureFrom,
+		arg.DepartureTo,
+	)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -95,11 +117,11 @@ func (q *Queries) CountReservations(ctx context.Context) (int64, error) {
 
 const createReservation = `-- name: CreateReservation :one
 INSERT INTO reservations (
-   passenger_id, schedule_id, wagon_id, seat_id, booking_date, reservation_status, discount_id, price, expires_at
+   passenger_id, schedule_id, wagon_id, seat_id, booking_date, reservation_status, discount_id, price, expires_at, order_id, from_stop, to_stop, passenger_type
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 ) 
-RETURNING id, passenger_id, schedule_id, wagon_id, seat_id, booking_date, discount_id, price, reservation_status, expires_at, created_at, updated_at
+RETURNING id, passenger_id, schedule_id, wagon_id, seat_id, booking_date, discount_id, price, reservation_status, expires_at, created_at, updated_at, order_id, from_stop, to_stop, passenger_type
 `
 
 type CreateReservationParams struct {
@@ -112,6 +134,10 @@ type CreateReservationParams struct {
 	DiscountID        pgtype.UUID       `db:"discount_id" json:"discount_id"`
 	Price             *int64            `db:"price" json:"price"`
 	ExpiresAt         pgtype.Timestamp  `db:"expires_at" json:"expires_at"`
+	OrderID           uuid.UUID         `db:"order_id" json:"order_id"`
+	FromStop          int32             `db:"from_stop" json:"from_stop"`
+	ToStop            int32             `db:"to_stop" json:"to_stop"`
+	PassengerType     string            `db:"passenger_type" json:"passenger_type"`
 }
 
 func (q *Queries) CreateReservation(ctx context.Context, arg CreateReservationParams) (Reservation, error) {
@@ -125,6 +151,10 @@ func (q *Queries) CreateReservation(ctx context.Context, arg CreateReservationPa
 		arg.DiscountID,
 		arg.Price,
 		arg.ExpiresAt,
+		arg.OrderID,
+		arg.FromStop,
+		arg.ToStop,
+		arg.PassengerType,
 	)
 	var i Reservation
 	err := row.Scan(
@@ -140,13 +170,17 @@ func (q *Queries) CreateReservation(ctx context.Context, arg CreateReservationPa
 		&i.ExpiresAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OrderID,
+		&i.FromStop,
+		&i.ToStop,
+		&i.PassengerType,
 	)
 	return i, err
 }
 
 const deleteReservation = `-- name: DeleteReservation :exec
 DELETE FROM reservations
-WHERE id = $1 AND reservation_status = 'canceled'
+WHERE id = $1 AND reservation_status = 'cancelled'
 `
 
 func (q *Queries) DeleteReservation(ctx context.Context, id uuid.UUID) error {
@@ -154,26 +188,10 @@ func (q *Queries) DeleteReservation(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
-const expireUndpaidReservations = `-- name: ExpireUndpaidReservations :exec
-
-
-DELETE FROM reservations
-WHERE expires_at < NOW() AND reservation_status = 'pending'
-`
-
-// -- name: CleanupExpiredHolds :exec
-// DELETE FROM seat_holds WHERE expires_at < NOW();
-// -- name: ExpireSeatHolds :exec
-// DELETE FROM seat_holds
-// WHERE expires_at < NOW();
-func (q *Queries) ExpireUndpaidReservations(ctx context.Context) error {
-	_, err := q.db.Exec(ctx, expireUndpaidReservations)
-	return err
-}
-
 const getFullReservation = `-- name: GetFullReservation :one
 SELECT 
 r.id AS reservation_id,
+o.booking_code,
 p.name AS passenger_name,
 p.id_number AS passenger_id_number,
 u.name AS user_name,
@@ -196,6 +214,7 @@ py.amount AS payment_amount,
 py.payment_method,
 py.payment_status 
 FROM reservations r
+JOIN orders o ON r.order_id = o.id
 LEFT JOIN passengers p ON r.passenger_id = p.id
 LEFT JOIN users u ON p.user_id = u.id
 LEFT JOIN schedules s ON r.schedule_id = s.id
@@ -204,12 +223,18 @@ LEFT JOIN wagons w ON r.wagon_id = w.id
 LEFT JOIN trains t ON s.train_id = t.id
 LEFT JOIN routes rt ON s.route_id = rt.id
 LEFT JOIN discount_codes d ON r.discount_id = d.id
-LEFT JOIN payments py ON r.id = py.reservation_id
+LEFT JOIN LATERAL (
+  SELECT amount, payment_method, payment_status FROM payments
+  WHERE reservation_id = r.id OR (reservation_id IS NULL AND order_id = r.order_id)
+  ORDER BY created_at DESC
+  LIMIT 1
+) py ON true
 WHERE r.id = $1
 `
 
 type GetFullReservationRow struct {
 	ReservationID      uuid.UUID         `db:"reservation_id" json:"reservation_id"`
+	BookingCode        string            `db:"booking_code" json:"booking_code"`
 	PassengerName      *string           `db:"passenger_name" json:"passenger_name"`
 	PassengerIDNumber  *string           `db:"passenger_id_number" json:"passenger_id_number"`
 	UserName           *string           `db:"user_name" json:"user_name"`
@@ -238,6 +263,7 @@ func (q *Queries) GetFullReservation(ctx context.Context, id uuid.UUID) (GetFull
 	var i GetFullReservationRow
 	err := row.Scan(
 		&i.ReservationID,
+		&i.BookingCode,
 		&i.PassengerName,
 		&i.PassengerIDNumber,
 		&i.UserName,
@@ -264,7 +290,7 @@ func (q *Queries) GetFullReservation(ctx context.Context, id uuid.UUID) (GetFull
 }
 
 const getReservation = `-- name: GetReservation :one
-SELECT id, passenger_id, schedule_id, wagon_id, seat_id, booking_date, discount_id, price, reservation_status, expires_at, created_at, updated_at FROM reservations
+SELECT id, passenger_id, schedule_id, wagon_id, seat_id, booking_date, discount_id, price, reservation_status, expires_at, created_at, updated_at, order_id, from_stop, to_stop, passenger_type FROM reservations
 WHERE id = $1 LIMIT 1
 `
 
@@ -284,13 +310,68 @@ func (q *Queries) GetReservation(ctx context.Context, id uuid.UUID) (Reservation
 		&i.ExpiresAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OrderID,
+		&i.FromStop,
+		&i.ToStop,
+		&i.PassengerType,
 	)
 	return i, err
 }
 
+const listExpiredReservations = `-- name: ListExpiredReservations :many
+
+
+SELECT id, passenger_id, schedule_id, wagon_id, seat_id, booking_date, discount_id, price, reservation_status, expires_at, created_at, updated_at, order_id, from_stop, to_stop, passenger_type FROM reservations
+WHERE expires_at < NOW() AND reservation_status = 'pending'
+ORDER BY expires_at
+`
+
+// -- name: CleanupExpiredHolds :exec
+// DELETE FROM seat_holds WHERE expires_at < NOW();
+// -- name: ExpireSeatHolds :exec
+// DELETE FROM seat_holds
+// WHERE expires_at < NOW();
+func (q *Queries) ListExpiredReservations(ctx context.Context) ([]Reservation, error) {
+	rows, err := q.db.Query(ctx, listExpiredReservations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Reservation{}
+	for rows.Next() {
+		var i Reservation
+		if err := rows.Scan(
+			&i.ID,
+			&i.PassengerID,
+			&i.ScheduleID,
+			&i.WagonID,
+			&i.SeatID,
+			&i.BookingDate,
+			&i.DiscountID,
+			&i.Price,
+			&i.ReservationStatus,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OrderID,
+			&i.FromStop,
+			&i.ToStop,
+			&i.PassengerType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listReservations = `-- name: ListReservations :many
 SELECT 
 r.id AS reservation_id,
+o.booking_code,
 p.name AS passenger_name,
 p.id_number AS passenger_id_number,
 u.name AS user_name,
@@ -313,6 +394,7 @@ py.amount AS payment_amount,
 py.payment_method,
 py.payment_status
 FROM reservations r
+JOIN orders o ON r.order_id = o.id
 LEFT JOIN passengers p ON r.passenger_id = p.id
 LEFT JOIN users u ON p.user_id = u.id
 LEFT JOIN schedules s ON r.schedule_id = s.id
@@ -321,19 +403,74 @@ LEFT JOIN wagons w ON r.wagon_id = w.id
 LEFT JOIN trains t ON s.train_id = t.id
 LEFT JOIN routes rt ON s.route_id = rt.id
 LEFT JOIN discount_codes d ON r.discount_id = d.id
-LEFT JOIN payments py ON r.id = py.reservation_id
-ORDER BY booking_date DESC
-LIMIT $1
-OFFSET $2
+LEFT JOIN LATERAL (
+  SELECT amount, payment_method, payment_status FROM payments
+  WHERE reservation_id = r.id OR (reservation_id IS NULL AND order_id = r.order_id)
+  ORDER BY created_at DESC
+  LIMIT 1
+) py ON true
+WHERE ($1::timestamp IS NULL OR s.departure_date >= $1::timestamp)
+  AND ($2::timestamp IS NULL OR s.departure_date < $2::timestamp)
+  AND ($3::bigint IS NULL OR s.route_id = $3::bigint)
+  AND ($4::bigint IS NULL OR s.train_id = $4::bigint)
+  AND ($5::text IS NULL OR r.reservation_status::text = $5::text)
+  AND ($6::text IS NULL OR p.name ILIKE '%' || regexp_replace($6::text, '([\\%_])', '\\\1', 'g') || '%' OR p.id_number = $6::text)
+  AND ($7::text IS NULL OR u.email ILIKE '%' || regexp_replace($7::text, '([\\%_])', '\\\1', 'g') || '%')
+  AND ($8::text IS NULL OR UPPER(d.code) = UPPER($8::text))
+ORDER BY
+  CASE WHEN NOT $9::bool THEN CASE $10::text
+    WHEN 'booking_date' THEN r.booking_date
+    WHEN 'departure_date' THEN s.departure_date
+    WHEN 'arrival_date' THEN s.arrival_date END END ASC,
+  CASE WHEN $9::bool THEN CASE $10::text
+    WHEN 'booking_date' THEN r.booking_date
+    WHEN 'departure_date' THEN s.departure_date
+    WHEN 'arrival_date' THEN s.arrival_date END END DESC,
+  CASE WHEN NOT $9::bool AND $10::text = 'ticket_price' THEN r.price END ASC,
+  CASE WHEN $9::bool AND $10::text = 'ticket_price' THEN r.price END DESC,
+  CASE WHEN NOT $9::bool THEN CASE $10::text
+    WHEN 'booking_code' THEN o.booking_code
+    WHEN 'passenger_name' THEN p.name
+    WHEN 'user_email' THEN u.email
+    WHEN 'train_name' THEN t.name
+    WHEN 'class_type' THEN w.class_type::text
+    WHEN 'reservation_status' THEN r.reservation_status::text
+    WHEN 'source_station' THEN rt.source_station
+    WHEN 'destination_station' THEN rt.destination_station
+    WHEN 'discount_code' THEN d.code END END ASC,
+  CASE WHEN $9::bool THEN CASE $10::text
+    WHEN 'booking_code' THEN o.booking_code
+    WHEN 'passenger_name' THEN p.name
+    WHEN 'user_email' THEN u.email
+    WHEN 'train_name' THEN t.name
+    WHEN 'class_type' THEN w.class_type::text
+    WHEN 'reservation_status' THEN r.reservation_status::text
+    WHEN 'source_station' THEN rt.source_station
+    WHEN 'destination_station' THEN rt.destination_station
+    WHEN 'discount_code' THEN d.code END END DESC,
+  r.booking_date DESC, r.id
+LIMIT $11
+OFFSET $12
 `
 
 type ListReservationsParams struct {
-	Limit  int32 `db:"limit" json:"limit"`
-	Offset int32 `db:"offset" json:"offset"`
+	DepartureFrom pgtype.Timestamp `db:"departure_from" json:"departure_from"`
+	DepartureTo   pgtype.Timestamp `db:"departure_to" json:"departure_to"`
+	RouteID       *int64           `db:"route_id" json:"route_id"`
+	TrainID       *int64           `db:"train_id" json:"train_id"`
+	Status        *string          `db:"status" json:"status"`
+	Passenger     *string          `db:"passenger" json:"passenger"`
+	Email         *string          `db:"email" json:"email"`
+	DiscountCode  *string          `db:"discount_code" json:"discount_code"`
+	SortDesc      bool             `db:"sort_desc" json:"sort_desc"`
+	SortBy        string           `db:"sort_by" json:"sort_by"`
+	PageLimit     int32            `db:"page_limit" json:"page_limit"`
+	PageOffset    int32            `db:"page_offset" json:"page_offset"`
 }
 
 type ListReservationsRow struct {
 	ReservationID      uuid.UUID         `db:"reservation_id" json:"reservation_id"`
+	BookingCode        string            `db:"booking_code" json:"booking_code"`
 	PassengerName      *string           `db:"passenger_name" json:"passenger_name"`
 	PassengerIDNumber  *string           `db:"passenger_id_number" json:"passenger_id_number"`
 	UserName           *string           `db:"user_name" json:"user_name"`
@@ -358,7 +495,20 @@ type ListReservationsRow struct {
 }
 
 func (q *Queries) ListReservations(ctx context.Context, arg ListReservationsParams) ([]ListReservationsRow, error) {
-	rows, err := q.db.Query(ctx, listReservations, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listReservations,
+		arg.DepartureFrom,
+		arg.DepartureTo,
+		arg.RouteID,
+		arg.TrainID,
+		arg.Status,
+		arg.Passenger,
+		arg.Email,
+		arg.DiscountCode,
+		arg.SortDesc,
+		arg.SortBy,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -368,6 +518,148 @@ func (q *Queries) ListReservations(ctx context.Context, arg ListReservationsPara
 		var i ListReservationsRow
 		if err := rows.Scan(
 			&i.ReservationID,
+			&i.BookingCode,
+			&i.PassengerName,
+			&i.PassengerIDNumber,
+			&i.UserName,
+			&i.UserEmail,
+			&i.DepartureDate,
+			&i.ArrivalDate,
+			&i.TicketPrice,
+			&i.TrainName,
+			&i.ClassType,
+			&i.WagonNumber,
+			&i.SeatNumber,
+			&i.SeatRow,
+			&i.BookingDate,
+			&i.ReservationStatus,
+			&i.SourceStation,
+			&i.DestinationStation,
+			&i.DiscountCode,
+			&i.DiscountPercent,
+			&i.PaymentAmount,
+			&i.PaymentMethod,
+			&i.PaymentStatus,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserReservations = `-- name: ListUserReservations :many
+SELECT 
+r.id AS reservation_id,
+o.booking_code,
+p.name AS passenger_name,
+p.id_number AS passenger_id_number,
+u.name AS user_name,
+u.email AS user_email,
+s.departure_date,
+s.arrival_date,
+r.price AS ticket_price,
+t.name AS train_name,
+w.class_type,
+w.wagon_number,
+st.seat_number,
+st.seat_row,
+r.booking_date,
+r.reservation_status,
+rt.source_station,
+rt.destination_station,
+d.code AS discount_code,
+d.discount_percent,
+py.amount AS payment_amount,
+py.payment_method,
+py.payment_status
+FROM reservations r
+JOIN orders o ON r.order_id = o.id
+LEFT JOIN passengers p ON r.passenger_id = p.id
+LEFT JOIN users u ON p.user_id = u.id
+LEFT JOIN schedules s ON r.schedule_id = s.id
+LEFT JOIN seats st ON r.seat_id = st.id
+LEFT JOIN wagons w ON r.wagon_id = w.id
+LEFT JOIN trains t ON s.train_id = t.id
+LEFT JOIN routes rt ON s.route_id = rt.id
+LEFT JOIN discount_codes d ON r.discount_id = d.id
+LEFT JOIN LATERAL (
+  SELECT amount, payment_method, payment_status FROM payments
+  WHERE reservation_id = r.id OR (reservation_id IS NULL AND order_id = r.order_id)
+  ORDER BY created_at DESC
+  LIMIT 1
+) py ON true
+WHERE (p.user_id = $1 OR p.session_id = $2)
+  AND (cardinality($3::text[]) = 0 OR r.reservation_status::text = ANY($3::text[]))
+  AND ($4::timestamp IS NULL OR s.departure_date >= $4::timestamp)
+  AND ($5::timestamp IS NULL OR s.departure_date < $5::timestamp)
+ORDER BY CASE WHEN $6::bool THEN s.departure_date END ASC,
+  s.departure_date DESC, r.booking_date DESC
+LIMIT $7
+OFFSET $8
+`
+
+type ListUserReservationsParams struct {
+	UserID        pgtype.UUID      `db:"user_id" json:"user_id"`
+	SessionID     *string          `db:"session_id" json:"session_id"`
+	Statuses      []string         `db:"statuses" json:"statuses"`
+	DepartureFrom pgtype.Timestamp `db:"departure_from" json:"departure_from"`
+	DepartureTo   pgtype.Timestamp `db:"departure_to" json:"departure_to"`
+	Ascending     bool             `db:"ascending" json:"ascending"`
+	PageLimit     int32            `db:"page_limit" json:"page_limit"`
+	PageOffset    int32            `db:"page_offset" json:"page_offset"`
+}
+
+type ListUserReservationsRow struct {
+	ReservationID      uuid.UUID         `db:"reservation_id" json:"reservation_id"`
+	BookingCode        string            `db:"booking_code" json:"booking_code"`
+	PassengerName      *string           `db:"passenger_name" json:"passenger_name"`
+	PassengerIDNumber  *string           `db:"passenger_id_number" json:"passenger_id_number"`
+	UserName           *string           `db:"user_name" json:"user_name"`
+	UserEmail          *string           `db:"user_email" json:"user_email"`
+	DepartureDate      pgtype.Timestamp  `db:"departure_date" json:"departure_date"`
+	ArrivalDate        pgtype.Timestamp  `db:"arrival_date" json:"arrival_date"`
+	TicketPrice        *int64            `db:"ticket_price" json:"ticket_price"`
+	TrainName          *string           `db:"train_name" json:"train_name"`
+	ClassType          NullTipeClass     `db:"class_type" json:"class_type"`
+	WagonNumber        *int32            `db:"wagon_number" json:"wagon_number"`
+	SeatNumber         *int32            `db:"seat_number" json:"seat_number"`
+	SeatRow            NullSeatRow       `db:"seat_row" json:"seat_row"`
+	BookingDate        pgtype.Timestamp  `db:"booking_date" json:"booking_date"`
+	ReservationStatus  StatusReservation `db:"reservation_status" json:"reservation_status"`
+	SourceStation      *string           `db:"source_station" json:"source_station"`
+	DestinationStation *string           `db:"destination_station" json:"destination_station"`
+	DiscountCode       *string           `db:"discount_code" json:"discount_code"`
+	DiscountPercent    *int32            `db:"discount_percent" json:"discount_percent"`
+	PaymentAmount      *int64            `db:"payment_amount" json:"payment_amount"`
+	PaymentMethod      *string           `db:"payment_method" json:"payment_method"`
+	PaymentStatus      *string           `db:"payment_status" json:"payment_status"`
+}
+
+func (q *Queries) ListUserReservations(ctx context.Context, arg ListUserReservationsParams) ([]ListUserReservationsRow, error) {
+	rows, err := q.db.Query(ctx, listUserReservations,
+		arg.UserID,
+		arg.SessionID,
+		arg.Statuses,
+		arg.DepartureFrom,
+		arg.DepartureTo,
+		arg.Ascending,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUserReservationsRow{}
+	for rows.Next() {
+		var i ListUserReservationsRow
+		if err := rows.Scan(
+			&i.ReservationID,
+			&i.BookingCode,
 			&i.PassengerName,
 			&i.PassengerIDNumber,
 			&i.UserName,
@@ -435,13 +727,51 @@ func (q *Queries) UpdateReservation(ctx context.Context, arg UpdateReservationPa
 	return err
 }
 
-const updateTrainCapacity = `-- name: UpdateTrainCapacity :exec
-UPDATE schedules
-SET available_seats = available_seats - 1
-WHERE id = $1 AND available_seats > 0
+const updateReservationStatus = `-- name: UpdateReservationStatus :execrows
+
+
+UPDATE reservations
+SET reservation_status = $1, updated_at = NOW()
+WHERE id = $2 AND reservation_status = $3
 `
 
-func (q *Queries) UpdateTrainCapacity(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, updateTrainCapacity, id)
-	return err
+type UpdateReservationStatusParams struct {
+	ToStatus   StatusReservation `db:"to_status" json:"to_status"`
+	ID         uuid.UUID         `db:"id" json:"id"`
+	FromStatus StatusReservation `db:"from_status" json:"from_status"`
+}
+
+// -- name: HoldSeat :exec
+// INSERT INTO seat_holds (passenger_id, schedule_id, wagon_id, seat_id, expires_at)
+// VALUES ($1, $2, $3, $4, NOW() + INTERVAL '15 minutes')
+// RETURNING *;
+// -- name: CreateReservationFromHold :one
+// WITH deleted_hold AS (
+//
+//	DELETE FROM seat_holds
+//	WHERE seat_holds.passenger_id = $1
+//	AND seat_holds.schedule_id = $2
+//	AND seat_holds.wagon_id = $3
+//	AND seat_holds.seat_id = $4
+//	RETURNING passenger_id, schedule_id, wagon_id, seat_id
+//
+// )
+// INSERT INTO reservations (
+//
+//	id, passenger_id, schedule_id, wagon_id, seat_id, booking_date, reservation_status, discount_id, price, expires_at
+//
+// -- )
+// SELECT
+//
+//	uuid_generate_v4(), deleted_hold.passenger_id, deleted_hold.schedule_id, deleted_hold.wagon_id, deleted_hold.seat_id,
+//	NOW(), 'pending', $5, $6, NOW() + INTERVAL '15 minutes'
+//
+// FROM deleted_hold
+// RETURNING *;
+func (q *Queries) UpdateReservationStatus(ctx context.Context, arg UpdateReservationStatusParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateReservationStatus, arg.ToStatus, arg.ID, arg.FromStatus)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
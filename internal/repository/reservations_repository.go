@@ -2,33 +2,47 @@ package repository
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
 	"fmt"
+	"railway-go/internal/constant/model"
 	"time"
+
+	"github.com/go-redis/redis/v8"
 )
 
 type ReservationRepository interface {
-	LockSeat(ctx context.Context, scheduleID, wagonID, seatID int64, duration time.Duration) error
-	UnlockSeat(ctx context.Context, scheduleID, wagonID, seatID int64) error
+	SaveSeatHold(ctx context.Context, hold model.SeatHold) error
+	GetSeatHold(ctx context.Context, id string) (model.SeatHold, error)
+	DeleteSeatHold(ctx context.Context, id string) error
 }
 
-const seatLock = "seat_lock:%d:%d:%d"
+const seatHold = "seat_hold:%s"
 
-func (r *redisRepository) LockSeat(ctx context.Context, scheduleID, wagonID, seatID int64, duration time.Duration) error {
-	holdKey := fmt.Sprintf(seatLock, scheduleID, wagonID, seatID)
-	exists, err := r.RedisClient.Exists(ctx, holdKey).Result()
+// SaveSeatHold stores a hold until its expiry, replacing any previous version of it.
+func (r *redisRepository) SaveSeatHold(ctx context.Context, hold model.SeatHold) error {
+	holdJson, err := json.Marshal(hold)
 	if err != nil {
 		return err
 	}
 
-	if exists > 0 {
-		return errors.New("seat already locked")
+	return r.RedisClient.Set(ctx, fmt.Sprintf(seatHold, hold.ID), holdJson, time.Until(hold.ExpiresAt)).Err()
+}
+
+func (r *redisRepository) GetSeatHold(ctx context.Context, id string) (model.SeatHold, error) {
+	val, err := r.RedisClient.Get(ctx, fmt.Sprintf(seatHold, id)).Bytes()
+	if err == redis.Nil {
+		return model.SeatHold{}, model.ErrHoldNotFound
+	} else if err != nil {
+		return model.SeatHold{}, err
 	}
 
-	return r.RedisClient.SetNX(ctx, holdKey, "locked", duration).Err()
+	var hold model.SeatHold
+	if err := json.Unmarshal(val, &hold); err != nil {
+		return model.SeatHold{}, err
+	}
+	return hold, nil
 }
 
-func (r *redisRepository) UnlockSeat(ctx context.Context, scheduleID, wagonID, seatID int64) error {
-	holdKey := fmt.Sprintf(seatLock, scheduleID, wagonID, seatID)
-	return r.RedisClient.Del(ctx, holdKey).Err()
+func (r *redisRepository) DeleteSeatHold(ctx context.Context, id string) error {
+	return r.RedisClient.Del(ctx, fmt.Sprintf(seatHold, id)).Err()
 }
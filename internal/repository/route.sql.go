@@ -9,6 +9,19 @@ import (
 	"context"
 )
 
+const countActiveRouteReservations = `-- name: CountActiveRouteReservations :one
+SELECT COUNT(*) FROM reservations r
+JOIN schedules s ON r.schedule_id = s.id
+WHERE s.route_id = $1 AND r.reservation_status IN ('pending', 'success')
+`
+
+func (q *Queries) CountActiveRouteReservations(ctx context.Context, routeID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, countActiveRouteReservations, routeID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createRoute = `-- name: CreateRoute :one
 INSERT INTO routes (
    source_station, destination_station, travel_time, created_at
@@ -38,6 +51,41 @@ func (q *Queries) CreateRoute(ctx context.Context, arg CreateRouteParams) (Route
 	return i, err
 }
 
+const createRouteStop = `-- name: CreateRouteStop :one
+INSERT INTO route_stops (
+   route_id, stop_order, station_code, minutes_from_start
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING id, route_id, stop_order, station_code, minutes_from_start, created_at
+`
+
+type CreateRouteStopParams struct {
+	RouteID          int64  `db:"route_id" json:"route_id"`
+	StopOrder        int32  `db:"stop_order" json:"stop_order"`
+	StationCode      string `db:"station_code" json:"station_code"`
+	MinutesFromStart int32  `db:"minutes_from_start" json:"minutes_from_start"`
+}
+
+func (q *Queries) CreateRouteStop(ctx context.Context, arg CreateRouteStopParams) (RouteStop, error) {
+	row := q.db.QueryRow(ctx, createRouteStop,
+		arg.RouteID,
+		arg.StopOrder,
+		arg.StationCode,
+		arg.MinutesFromStart,
+	)
+	var i RouteStop
+	err := row.Scan(
+		&i.ID,
+		&i.RouteID,
+		&i.StopOrder,
+		&i.StationCode,
+		&i.MinutesFromStart,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const deleteRoute = `-- name: DeleteRoute :exec
 DELETE FROM routes
 WHERE id = $1
@@ -48,6 +96,16 @@ func (q *Queries) DeleteRoute(ctx context.Context, id int64) error {
 	return err
 }
 
+const deleteRouteStops = `-- name: DeleteRouteStops :exec
+DELETE FROM route_stops
+WHERE route_id = $1
+`
+
+func (q *Queries) DeleteRouteStops(ctx context.Context, routeID int64) error {
+	_, err := q.db.Exec(ctx, deleteRouteStops, routeID)
+	return err
+}
+
 const getRoute = `-- name: GetRoute :one
 SELECT id, source_station, destination_station, travel_time, created_at, updated_at FROM  routes
 WHERE id = $1 LIMIT 1
@@ -99,6 +157,72 @@ func (q *Queries) ListRoute(ctx context.Context) ([]Route, error) {
 	return items, nil
 }
 
+const listRouteStops = `-- name: ListRouteStops :many
+SELECT id, route_id, stop_order, station_code, minutes_from_start, created_at FROM route_stops
+WHERE route_id = $1
+ORDER BY stop_order
+`
+
+func (q *Queries) ListRouteStops(ctx context.Context, routeID int64) ([]RouteStop, error) {
+	rows, err := q.db.Query(ctx, listRouteStops, routeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RouteStop{}
+	for rows.Next() {
+		var i RouteStop
+		if err := rows.Scan(
+			&i.ID,
+			&i.RouteID,
+			&i.StopOrder,
+			&i.StationCode,
+			&i.MinutesFromStart,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRouteStopsByRoutes = `-- name: ListRouteStopsByRoutes :many
+SELECT id, route_id, stop_order, station_code, minutes_from_start, created_at FROM route_stops
+WHERE route_id = ANY($1::bigint[])
+ORDER BY route_id, stop_order
+`
+
+func (q *Queries) ListRouteStopsByRoutes(ctx context.Context, routeIds []int64) ([]RouteStop, error) {
+	rows, err := q.db.Query(ctx, listRouteStopsByRoutes, routeIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RouteStop{}
+	for rows.Next() {
+		var i RouteStop
+		if err := rows.Scan(
+			&i.ID,
+			&i.RouteID,
+			&i.StopOrder,
+			&i.StationCode,
+			&i.MinutesFromStart,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateRoute = `-- name: UpdateRoute :exec
 UPDATE routes
   set source_station = $2,
@@ -13,20 +13,19 @@ import (
 
 const createSchedule = `-- name: CreateSchedule :one
 INSERT INTO schedules (
-   train_id, departure_date, arrival_date, available_seats, price, route_id
+   train_id, departure_date, arrival_date, price, route_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    $1, $2, $3, $4, $5
 )
-RETURNING id, train_id, route_id, departure_date, arrival_date, available_seats, price, created_at, updated_at
+RETURNING id, train_id, route_id, departure_date, arrival_date, price, created_at, updated_at
 `
 
 type CreateScheduleParams struct {
-	TrainID        int64            `db:"train_id" json:"train_id"`
-	DepartureDate  pgtype.Timestamp `db:"departure_date" json:"departure_date"`
-	ArrivalDate    pgtype.Timestamp `db:"arrival_date" json:"arrival_date"`
-	AvailableSeats int32            `db:"available_seats" json:"available_seats"`
-	Price          int64            `db:"price" json:"price"`
-	RouteID        int64            `db:"route_id" json:"route_id"`
+	TrainID       int64            `db:"train_id" json:"train_id"`
+	DepartureDate pgtype.Timestamp `db:"departure_date" json:"departure_date"`
+	ArrivalDate   pgtype.Timestamp `db:"arrival_date" json:"arrival_date"`
+	Price         int64            `db:"price" json:"price"`
+	RouteID       int64            `db:"route_id" json:"route_id"`
 }
 
 func (q *Queries) CreateSchedule(ctx context.Context, arg CreateScheduleParams) (Schedule, error) {
@@ -34,7 +33,6 @@ func (q *Queries) CreateSchedule(ctx context.Context, arg CreateScheduleParams)
 		arg.TrainID,
 		arg.DepartureDate,
 		arg.ArrivalDate,
-		arg.AvailableSeats,
 		arg.Price,
 		arg.RouteID,
 	)
@@ -45,7 +43,6 @@ func (q *Queries) CreateSchedule(ctx context.Context, arg CreateScheduleParams)
 		&i.RouteID,
 		&i.DepartureDate,
 		&i.ArrivalDate,
-		&i.AvailableSeats,
 		&i.Price,
 		&i.CreatedAt,
 		&i.UpdatedAt,
@@ -64,7 +61,7 @@ func (q *Queries) DeleteSchedule(ctx context.Context, id int64) error {
 }
 
 const getSchedule = `-- name: GetSchedule :one
-SELECT id, train_id, route_id, departure_date, arrival_date, available_seats, price, created_at, updated_at FROM  schedules
+SELECT id, train_id, route_id, departure_date, arrival_date, price, created_at, updated_at FROM  schedules
 WHERE id = $1 LIMIT 1
 `
 
@@ -77,7 +74,6 @@ func (q *Queries) GetSchedule(ctx context.Context, id int64) (Schedule, error) {
 		&i.RouteID,
 		&i.DepartureDate,
 		&i.ArrivalDate,
-		&i.AvailableSeats,
 		&i.Price,
 		&i.CreatedAt,
 		&i.UpdatedAt,
@@ -85,8 +81,173 @@ func (q *Queries) GetSchedule(ctx context.Context, id int64) (Schedule, error) {
 	return i, err
 }
 
+const listDepartingSchedules = `-- name: ListDepartingSchedules :many
+SELECT
+  s.id,
+  s.train_id,
+  s.route_id,
+  s.departure_date,
+  s.arrival_date,
+  s.price,
+  t.name AS train_name,
+  rt.source_station,
+  rt.destination_station,
+  rt.travel_time
+FROM schedules s
+JOIN trains t ON s.train_id = t.id
+JOIN routes rt ON s.route_id = rt.id
+WHERE s.departure_date >= $1 AND s.departure_date < $2
+ORDER BY s.departure_date
+`
+
+type ListDepartingSchedulesParams struct {
+	WindowStart pgtype.Timestamp `db:"window_start" json:"window_start"`
+	WindowEnd   pgtype.Timestamp `db:"window_end" json:"window_end"`
+}
+
+type ListDepartingSchedulesRow struct {
+	ID                 int64            `db:"id" json:"id"`
+	TrainID            int64            `db:"train_id" json:"train_id"`
+	RouteID            int64            `db:"route_id" json:"route_id"`
+	DepartureDate      pgtype.Timestamp `db:"departure_date" json:"departure_date"`
+	ArrivalDate        pgtype.Timestamp `db:"arrival_date" json:"arrival_date"`
+	Price              int64            `db:"price" json:"price"`
+	TrainName          string           `db:"train_name" json:"train_name"`
+	SourceStation      string           `db:"source_station" json:"source_station"`
+	DestinationStation string           `db:"destination_station" json:"destination_station"`
+	TravelTime         int32            `db:"travel_time" json:"travel_time"`
+}
+
+func (q *Queries) ListDepartingSchedules(ctx context.Context, arg ListDepartingSchedulesParams) ([]ListDepartingSchedulesRow, error) {
+	rows, err := q.db.Query(ctx, listDepartingSchedules, arg.WindowStart, arg.WindowEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDepartingSchedulesRow{}
+	for rows.Next() {
+		var i ListDepartingSchedulesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TrainID,
+			&i.RouteID,
+			&i.DepartureDate,
+			&i.ArrivalDate,
+			&i.Price,
+			&i.TrainName,
+			&i.SourceStation,
+			&i.DestinationStation,
+			&i.TravelTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listScheduleAvailability = `-- name: ListScheduleAvailability :many
+SELECT
+  s.id AS schedule_id,
+  w.class_type,
+  COUNT(DISTINCT st.id) FILTER (WHERE st.is_available IS NOT FALSE) AS total_seats,
+  COUNT(DISTINCT st.id) FILTER (WHERE st.is_available IS NOT FALSE AND r.id IS NULL) AS available_seats
+FROM schedules s
+JOIN wagons w ON w.train_id = s.train_id
+JOIN seats st ON st.wagon_id = w.id
+LEFT JOIN reservations r ON r.schedule_id = s.id AND r.wagon_id = w.id AND r.seat_id = st.id
+  AND r.reservation_status IN ('pending', 'success')
+WHERE s.id = ANY($1::bigint[])
+GROUP BY s.id, w.class_type
+ORDER BY s.id, w.class_type
+`
+
+type ListScheduleAvailabilityRow struct {
+	ScheduleID     int64     `db:"schedule_id" json:"schedule_id"`
+	ClassType      TipeClass `db:"class_type" json:"class_type"`
+	TotalSeats     int64     `db:"total_seats" json:"total_seats"`
+	AvailableSeats int64     `db:"available_seats" json:"available_seats"`
+}
+
+func (q *Queries) ListScheduleAvailability(ctx context.Context, scheduleIds []int64) ([]ListScheduleAvailabilityRow, error) {
+	rows, err := q.db.Query(ctx, listScheduleAvailability, scheduleIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListScheduleAvailabilityRow{}
+	for rows.Next() {
+		var i ListScheduleAvailabilityRow
+		if err := rows.Scan(
+			&i.ScheduleID,
+			&i.ClassType,
+			&i.TotalSeats,
+			&i.AvailableSeats,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listScheduleBookedLegs = `-- name: ListScheduleBookedLegs :many
+SELECT DISTINCT schedule_id, class_type, booked_legs FROM (
+  SELECT
+    s.id AS schedule_id,
+    w.class_type,
+    COALESCE(BIT_OR(((1::bigint << (r.to_stop - r.from_stop)) - 1) << r.from_stop), 0)::bigint AS booked_legs
+  FROM schedules s
+  JOIN wagons w ON w.train_id = s.train_id
+  JOIN seats st ON st.wagon_id = w.id
+  LEFT JOIN reservations r ON r.schedule_id = s.id AND r.wagon_id = w.id AND r.seat_id = st.id
+    AND r.reservation_status IN ('pending', 'success')
+  WHERE s.id = ANY($1::bigint[]) AND st.is_available IS NOT FALSE
+  GROUP BY s.id, w.class_type, st.id
+) seat_legs
+ORDER BY schedule_id, class_type
+`
+
+type ListScheduleBookedLegsRow struct {
+	ScheduleID int64     `db:"schedule_id" json:"schedule_id"`
+	ClassType  TipeClass `db:"class_type" json:"class_type"`
+	BookedLegs int64     `db:"booked_legs" json:"booked_legs"`
+}
+
+// ListScheduleBookedLegs groups the bookable seats of the schedules by class and by the legs their
+// pending and paid reservations occupy, bit n of booked_legs is leg n of the route.
+func (q *Queries) ListScheduleBookedLegs(ctx context.Context, scheduleIds []int64) ([]ListScheduleBookedLegsRow, error) {
+	rows, err := q.db.Query(ctx, listScheduleBookedLegs, scheduleIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListScheduleBookedLegsRow{}
+	for rows.Next() {
+		var i ListScheduleBookedLegsRow
+		if err := rows.Scan(
+			&i.ScheduleID,
+			&i.ClassType,
+			&i.BookedLegs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listSchedules = `-- name: ListSchedules :many
-SELECT id, train_id, route_id, departure_date, arrival_date, available_seats, price, created_at, updated_at FROM schedules
+SELECT id, train_id, route_id, departure_date, arrival_date, price, created_at, updated_at FROM schedules
 ORDER BY departure_date
 `
 
@@ -105,7 +266,6 @@ func (q *Queries) ListSchedules(ctx context.Context) ([]Schedule, error) {
 			&i.RouteID,
 			&i.DepartureDate,
 			&i.ArrivalDate,
-			&i.AvailableSeats,
 			&i.Price,
 			&i.CreatedAt,
 			&i.UpdatedAt,
@@ -128,7 +288,6 @@ SELECT
   r.destination_station AS destination_station,
   s.departure_date,
   s.arrival_date,
-  s.available_seats,
   s.price
 FROM schedules s
 JOIN routes r ON s.route_id = r.id
@@ -153,7 +312,6 @@ type SearchSchedulesRow struct {
 	DestinationStation string           `db:"destination_station" json:"destination_station"`
 	DepartureDate      pgtype.Timestamp `db:"departure_date" json:"departure_date"`
 	ArrivalDate        pgtype.Timestamp `db:"arrival_date" json:"arrival_date"`
-	AvailableSeats     int32            `db:"available_seats" json:"available_seats"`
 	Price              int64            `db:"price" json:"price"`
 }
 
@@ -173,7 +331,6 @@ func (q *Queries) SearchSchedules(ctx context.Context, arg SearchSchedulesParams
 			&i.DestinationStation,
 			&i.DepartureDate,
 			&i.ArrivalDate,
-			&i.AvailableSeats,
 			&i.Price,
 		); err != nil {
 			return nil, err
@@ -192,19 +349,17 @@ UPDATE schedules
   route_id = $3,
   departure_date = $4,
   arrival_date = $5,
-  price = $6,
-  available_seats = $7
+  price = $6
 WHERE id = $1
 `
 
 type UpdateScheduleParams struct {
-	ID             int64            `db:"id" json:"id"`
-	TrainID        int64            `db:"train_id" json:"train_id"`
-	RouteID        int64            `db:"route_id" json:"route_id"`
-	DepartureDate  pgtype.Timestamp `db:"departure_date" json:"departure_date"`
-	ArrivalDate    pgtype.Timestamp `db:"arrival_date" json:"arrival_date"`
-	Price          int64            `db:"price" json:"price"`
-	AvailableSeats int32            `db:"available_seats" json:"available_seats"`
+	ID            int64            `db:"id" json:"id"`
+	TrainID       int64            `db:"train_id" json:"train_id"`
+	RouteID       int64            `db:"route_id" json:"route_id"`
+	DepartureDate pgtype.Timestamp `db:"departure_date" json:"departure_date"`
+	ArrivalDate   pgtype.Timestamp `db:"arrival_date" json:"arrival_date"`
+	Price         int64            `db:"price" json:"price"`
 }
 
 func (q *Queries) UpdateSchedule(ctx context.Context, arg UpdateScheduleParams) error {
@@ -215,7 +370,6 @@ func (q *Queries) UpdateSchedule(ctx context.Context, arg UpdateScheduleParams)
 		arg.DepartureDate,
 		arg.ArrivalDate,
 		arg.Price,
-		arg.AvailableSeats,
 	)
 	return err
 }
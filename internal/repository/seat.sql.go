@@ -72,6 +72,76 @@ func (q *Queries) GetSeat(ctx context.Context, id int64) (Seat, error) {
 	return i, err
 }
 
+const listScheduleSeats = `-- name: ListScheduleSeats :many
+SELECT
+w.id AS wagon_id,
+w.wagon_number,
+w.class_type,
+st.id AS seat_id,
+st.seat_number,
+st.seat_row,
+st.is_available,
+COUNT(r.id) AS active_reservations,
+COALESCE(BOOL_OR(r.reservation_status = 'success'), false)::boolean AS paid
+FROM schedules s
+JOIN wagons w ON w.train_id = s.train_id
+JOIN seats st ON st.wagon_id = w.id
+LEFT JOIN reservations r ON r.schedule_id = s.id AND r.wagon_id = w.id AND r.seat_id = st.id
+  AND r.reservation_status IN ('pending', 'success')
+  AND int4range(r.from_stop, r.to_stop) && int4range($1::int, $2::int)
+WHERE s.id = $3
+GROUP BY w.id, st.id
+ORDER BY w.wagon_number, w.id, st.seat_number, st.seat_row
+`
+
+type ListScheduleSeatsParams struct {
+	FromStop   int32 `db:"from_stop" json:"from_stop"`
+	ToStop     int32 `db:"to_stop" json:"to_stop"`
+	ScheduleID int64 `db:"schedule_id" json:"schedule_id"`
+}
+
+type ListScheduleSeatsRow struct {
+	WagonID            int64     `db:"wagon_id" json:"wagon_id"`
+	WagonNumber        int32     `db:"wagon_number" json:"wagon_number"`
+	ClassType          TipeClass `db:"class_type" json:"class_type"`
+	SeatID             int64     `db:"seat_id" json:"seat_id"`
+	SeatNumber         int32     `db:"seat_number" json:"seat_number"`
+	SeatRow            SeatRow   `db:"seat_row" json:"seat_row"`
+	IsAvailable        *bool     `db:"is_available" json:"is_available"`
+	ActiveReservations int64     `db:"active_reservations" json:"active_reservations"`
+	Paid               bool      `db:"paid" json:"paid"`
+}
+
+func (q *Queries) ListScheduleSeats(ctx context.Context, arg ListScheduleSeatsParams) ([]ListScheduleSeatsRow, error) {
+	rows, err := q.db.Query(ctx, listScheduleSeats, arg.FromStop, arg.ToStop, arg.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListScheduleSeatsRow{}
+	for rows.Next() {
+		var i ListScheduleSeatsRow
+		if err := rows.Scan(
+			&i.WagonID,
+			&i.WagonNumber,
+			&i.ClassType,
+			&i.SeatID,
+			&i.SeatNumber,
+			&i.SeatRow,
+			&i.IsAvailable,
+			&i.ActiveReservations,
+			&i.Paid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listSeats = `-- name: ListSeats :many
 SELECT id, wagon_id, seat_number, seat_row, is_available, created_at, updated_at FROM seats
 WHERE wagon_id = $1
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"railway-go/internal/constant/model"
+	"sync"
+	"time"
+)
+
+type seatLockKey struct {
+	ScheduleID int64
+	WagonID    int64
+	SeatID     int64
+}
+
+type memorySeatLock struct {
+	owner     string
+	expiresAt time.Time
+}
+
+// memorySeatLocker keeps seat locks in the process. Locks are not shared between
+// instances, so it only suits tests and single node deployments.
+type memorySeatLocker struct {
+	mu    sync.Mutex
+	locks map[seatLockKey]memorySeatLock
+}
+
+func NewMemorySeatLocker() SeatLocker {
+	return &memorySeatLocker{locks: make(map[seatLockKey]memorySeatLock)}
+}
+
+// live returns the unexpired lock of a seat, dropping it when it has expired. Callers hold mu.
+func (l *memorySeatLocker) live(key seatLockKey) (memorySeatLock, bool) {
+	lock, ok := l.locks[key]
+	if ok && !time.Now().Before(lock.expiresAt) {
+		delete(l.locks, key)
+		return memorySeatLock{}, false
+	}
+	return lock, ok
+}
+
+func (l *memorySeatLocker) LockSeat(ctx context.Context, scheduleID, wagonID, seatID int64, owner string, duration time.Duration) error {
+	if owner == "" {
+		return errSeatLockOwner
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := seatLockKey{ScheduleID: scheduleID, WagonID: wagonID, SeatID: seatID}
+	if _, ok := l.live(key); ok {
+		return model.ErrSeatAlreadyLocked
+	}
+	l.locks[key] = memorySeatLock{owner: owner, expiresAt: time.Now().Add(duration)}
+	return nil
+}
+
+func (l *memorySeatLocker) UnlockSeat(ctx context.Context, scheduleID, wagonID, seatID int64, owner string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := seatLockKey{ScheduleID: scheduleID, WagonID: wagonID, SeatID: seatID}
+	lock, ok := l.live(key)
+	if !ok || lock.owner != owner {
+		return model.ErrSeatNotLocked
+	}
+	delete(l.locks, key)
+	return nil
+}
+
+func (l *memorySeatLocker) ExtendSeatLock(ctx context.Context, scheduleID, wagonID, seatID int64, owner string, duration time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := seatLockKey{ScheduleID: scheduleID, WagonID: wagonID, SeatID: seatID}
+	lock, ok := l.live(key)
+	if !ok || lock.owner != owner {
+		return model.ErrSeatNotLocked
+	}
+	lock.expiresAt = time.Now().Add(duration)
+	l.locks[key] = lock
+	return nil
+}
+
+func (l *memorySeatLocker) LockedSeats(ctx context.Context, scheduleID int64, seats []SeatRef) (map[SeatRef]bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	locked := make(map[SeatRef]bool)
+	for _, seat := range seats {
+		if _, ok := l.live(seatLockKey{ScheduleID: scheduleID, WagonID: seat.WagonID, SeatID: seat.SeatID}); ok {
+			locked[seat] = true
+		}
+	}
+	return locked, nil
+}
+
+// PurgeExpiredSeatLocks drops the expired locks of seats that were never looked at again.
+func (l *memorySeatLocker) PurgeExpiredSeatLocks(ctx context.Context) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var purged int64
+	for key := range l.locks {
+		if _, ok := l.live(key); !ok {
+			purged++
+		}
+	}
+	return purged, nil
+}
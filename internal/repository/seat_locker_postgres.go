@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"railway-go/internal/constant/model"
+	"time"
+)
+
+// postgresSeatLocker keeps seat locks in the seat_locks table. Every call runs on its own
+// outside any booking transaction, so a lock is visible to other requests as soon as it is taken.
+type postgresSeatLocker struct {
+	queries *Queries
+}
+
+func NewPostgresSeatLocker(db DBTX) SeatLocker {
+	return &postgresSeatLocker{queries: New(db)}
+}
+
+func (l *postgresSeatLocker) LockSeat(ctx context.Context, scheduleID, wagonID, seatID int64, owner string, duration time.Duration) error {
+	if owner == "" {
+		return errSeatLockOwner
+	}
+
+	acquired, err := l.queries.AcquireSeatLock(ctx, AcquireSeatLockParams{
+		ScheduleID: scheduleID,
+		WagonID:    wagonID,
+		SeatID:     seatID,
+		Owner:      owner,
+		TtlMs:      duration.Milliseconds(),
+	})
+	if err != nil {
+		return err
+	}
+	if acquired == 0 {
+		return model.ErrSeatAlreadyLocked
+	}
+	return nil
+}
+
+func (l *postgresSeatLocker) UnlockSeat(ctx context.Context, scheduleID, wagonID, seatID int64, owner string) error {
+	released, err := l.queries.ReleaseSeatLock(ctx, ReleaseSeatLockParams{
+		ScheduleID: scheduleID,
+		WagonID:    wagonID,
+		SeatID:     seatID,
+		Owner:      owner,
+	})
+	if err != nil {
+		return err
+	}
+	if released == 0 {
+		return model.ErrSeatNotLocked
+	}
+	return nil
+}
+
+func (l *postgresSeatLocker) ExtendSeatLock(ctx context.Context, scheduleID, wagonID, seatID int64, owner string, duration time.Duration) error {
+	extended, err := l.queries.RefreshSeatLock(ctx, RefreshSeatLockParams{
+		TtlMs:      duration.Milliseconds(),
+		ScheduleID: scheduleID,
+		WagonID:    wagonID,
+		SeatID:     seatID,
+		Owner:      owner,
+	})
+	if err != nil {
+		return err
+	}
+	if extended == 0 {
+		return model.ErrSeatNotLocked
+	}
+	return nil
+}
+
+func (l *postgresSeatLocker) LockedSeats(ctx context.Context, scheduleID int64, seats []SeatRef) (map[SeatRef]bool, error) {
+	locked := make(map[SeatRef]bool)
+	if len(seats) == 0 {
+		return locked, nil
+	}
+
+	rows, err := l.queries.ListActiveSeatLocks(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	active := make(map[SeatRef]bool, len(rows))
+	for _, row := range rows {
+		active[SeatRef{WagonID: row.WagonID, SeatID: row.SeatID}] = true
+	}
+	for _, seat := range seats {
+		if active[seat] {
+			locked[seat] = true
+		}
+	}
+	return locked, nil
+}
+
+// PurgeExpiredSeatLocks deletes the rows of locks that expired without being released. An
+// expired row never blocks a seat, it is only taken over in place by the next lock.
+func (l *postgresSeatLocker) PurgeExpiredSeatLocks(ctx context.Context) (int64, error) {
+	return l.queries.DeleteExpiredSeatLocks(ctx)
+}
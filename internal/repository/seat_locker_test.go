@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"railway-go/internal/constant/model"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/jackc/pgx/v5"
+)
+
+// seatLockerBackend builds a fresh locker and a clock to move past lock expiries.
+type seatLockerBackend struct {
+	name    string
+	newLock func(t *testing.T) (SeatLocker, func(time.Duration))
+}
+
+var seatLockerBackends = []seatLockerBackend{
+	{
+		name: "memory",
+		newLock: func(t *testing.T) (SeatLocker, func(time.Duration)) {
+			return NewMemorySeatLocker(), time.Sleep
+		},
+	},
+	{
+		name: "redis",
+		newLock: func(t *testing.T) (SeatLocker, func(time.Duration)) {
+			server := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+			t.Cleanup(func() { client.Close() })
+			return NewRedisSeatLocker(client), server.FastForward
+		},
+	},
+	{
+		// runs against the database in DB_SOURCE, migrated up to the seat_locks table. The
+		// locks go to a temporary table of the connection, so no schedule rows are needed.
+		name: "postgres",
+		newLock: func(t *testing.T) (SeatLocker, func(time.Duration)) {
+			source := os.Getenv("DB_SOURCE")
+			if source == "" {
+				t.Skip("DB_SOURCE is not set")
+			}
+			ctx := context.Background()
+			conn, err := pgx.Connect(ctx, source)
+			if err != nil {
+				t.Fatalf("connect: %v", err)
+			}
+			t.Cleanup(func() { conn.Close(ctx) })
+			if _, err := conn.Exec(ctx, "CREATE TEMPORARY TABLE seat_locks (LIKE public.seat_locks INCLUDING ALL)"); err != nil {
+				t.Fatalf("create seat_locks: %v", err)
+			}
+			return NewPostgresSeatLocker(conn), time.Sleep
+		},
+	},
+}
+
+const (
+	lockSchedule = 1
+	lockWagon    = 2
+	lockSeat     = 3
+	shortTTL     = 200 * time.Millisecond
+)
+
+func TestSeatLocker(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, ctx context.Context, locker SeatLocker, advance func(time.Duration))
+	}{
+		{
+			name: "lock is exclusive",
+			run: func(t *testing.T, ctx context.Context, locker SeatLocker, advance func(time.Duration)) {
+				mustLock(t, ctx, locker, "alice", time.Minute)
+				for _, owner := range []string{"bob", "alice"} {
+					err := locker.LockSeat(ctx, lockSchedule, lockWagon, lockSeat, owner, time.Minute)
+					if !errors.Is(err, model.ErrSeatAlreadyLocked) {
+						t.Fatalf("lock by %s: got %v, want %v", owner, err, model.ErrSeatAlreadyLocked)
+					}
+				}
+				// another seat of the same wagon is not affected
+				if err := locker.LockSeat(ctx, lockSchedule, lockWagon, lockSeat+1, "bob", time.Minute); err != nil {
+					t.Fatalf("lock other seat: %v", err)
+				}
+			},
+		},
+		{
+			name: "lock needs an owner",
+			run: func(t *testing.T, ctx context.Context, locker SeatLocker, advance func(time.Duration)) {
+				if err := locker.LockSeat(ctx, lockSchedule, lockWagon, lockSeat, "", time.Minute); err == nil {
+					t.Fatal("lock without owner succeeded")
+				}
+			},
+		},
+		{
+			name: "unlock frees the seat",
+			run: func(t *testing.T, ctx context.Context, locker SeatLocker, advance func(time.Duration)) {
+				mustLock(t, ctx, locker, "alice", time.Minute)
+				if err := locker.UnlockSeat(ctx, lockSchedule, lockWagon, lockSeat, "alice"); err != nil {
+					t.Fatalf("unlock: %v", err)
+				}
+				assertLocked(t, ctx, locker, false)
+				mustLock(t, ctx, locker, "bob", time.Minute)
+			},
+		},
+		{
+			name: "unlock without a lock",
+			run: func(t *testing.T, ctx context.Context, locker SeatLocker, advance func(time.Duration)) {
+				err := locker.UnlockSeat(ctx, lockSchedule, lockWagon, lockSeat, "alice")
+				if !errors.Is(err, model.ErrSeatNotLocked) {
+					t.Fatalf("got %v, want %v", err, model.ErrSeatNotLocked)
+				}
+			},
+		},
+		{
+			name: "wrong owner cannot unlock or extend",
+			run: func(t *testing.T, ctx context.Context, locker SeatLocker, advance func(time.Duration)) {
+				mustLock(t, ctx, locker, "alice", shortTTL)
+				if err := locker.UnlockSeat(ctx, lockSchedule, lockWagon, lockSeat, "bob"); !errors.Is(err, model.ErrSeatNotLocked) {
+					t.Fatalf("unlock: got %v, want %v", err, model.ErrSeatNotLocked)
+				}
+				if err := locker.ExtendSeatLock(ctx, lockSchedule, lockWagon, lockSeat, "bob", time.Minute); !errors.Is(err, model.ErrSeatNotLocked) {
+					t.Fatalf("extend: got %v, want %v", err, model.ErrSeatNotLocked)
+				}
+				assertLocked(t, ctx, locker, true)
+
+				// the failed extension left the original expiry in place
+				advance(2 * shortTTL)
+				assertLocked(t, ctx, locker, false)
+			},
+		},
+		{
+			name: "extend keeps the lock past its expiry",
+			run: func(t *testing.T, ctx context.Context, locker SeatLocker, advance func(time.Duration)) {
+				mustLock(t, ctx, locker, "alice", shortTTL)
+				if err := locker.ExtendSeatLock(ctx, lockSchedule, lockWagon, lockSeat, "alice", time.Minute); err != nil {
+					t.Fatalf("extend: %v", err)
+				}
+				advance(2 * shortTTL)
+				assertLocked(t, ctx, locker, true)
+				if err := locker.LockSeat(ctx, lockSchedule, lockWagon, lockSeat, "bob", time.Minute); !errors.Is(err, model.ErrSeatAlreadyLocked) {
+					t.Fatalf("lock: got %v, want %v", err, model.ErrSeatAlreadyLocked)
+				}
+			},
+		},
+		{
+			name: "expired lock is released",
+			run: func(t *testing.T, ctx context.Context, locker SeatLocker, advance func(time.Duration)) {
+				mustLock(t, ctx, locker, "alice", shortTTL)
+				advance(2 * shortTTL)
+
+				assertLocked(t, ctx, locker, false)
+				if err := locker.UnlockSeat(ctx, lockSchedule, lockWagon, lockSeat, "alice"); !errors.Is(err, model.ErrSeatNotLocked) {
+					t.Fatalf("unlock: got %v, want %v", err, model.ErrSeatNotLocked)
+				}
+				if err := locker.ExtendSeatLock(ctx, lockSchedule, lockWagon, lockSeat, "alice", time.Minute); !errors.Is(err, model.ErrSeatNotLocked) {
+					t.Fatalf("extend: got %v, want %v", err, model.ErrSeatNotLocked)
+				}
+				mustLock(t, ctx, locker, "bob", time.Minute)
+			},
+		},
+		{
+			name: "purge keeps live locks",
+			run: func(t *testing.T, ctx context.Context, locker SeatLocker, advance func(time.Duration)) {
+				if err := locker.LockSeat(ctx, lockSchedule, lockWagon, lockSeat+1, "bob", shortTTL); err != nil {
+					t.Fatalf("lock: %v", err)
+				}
+				mustLock(t, ctx, locker, "alice", time.Minute)
+				advance(2 * shortTTL)
+
+				if _, err := locker.PurgeExpiredSeatLocks(ctx); err != nil {
+					t.Fatalf("purge: %v", err)
+				}
+				assertLocked(t, ctx, locker, true)
+				if err := locker.UnlockSeat(ctx, lockSchedule, lockWagon, lockSeat, "alice"); err != nil {
+					t.Fatalf("unlock after purge: %v", err)
+				}
+			},
+		},
+	}
+
+	for _, backend := range seatLockerBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					locker, advance := backend.newLock(t)
+					tt.run(t, context.Background(), locker, advance)
+				})
+			}
+		})
+	}
+}
+
+func mustLock(t *testing.T, ctx context.Context, locker SeatLocker, owner string, ttl time.Duration) {
+	t.Helper()
+	if err := locker.LockSeat(ctx, lockSchedule, lockWagon, lockSeat, owner, ttl); err != nil {
+		t.Fatalf("lock by %s: %v", owner, err)
+	}
+}
+
+func assertLocked(t *testing.T, ctx context.Context, locker SeatLocker, want bool) {
+	t.Helper()
+	seat := SeatRef{WagonID: lockWagon, SeatID: lockSeat}
+	locked, err := locker.LockedSeats(ctx, lockSchedule, []SeatRef{seat, {WagonID: lockWagon, SeatID: lockSeat + 100}})
+	if err != nil {
+		t.Fatalf("locked seats: %v", err)
+	}
+	if locked[seat] != want {
+		t.Fatalf("seat locked = %v, want %v", locked[seat], want)
+	}
+	if locked[SeatRef{WagonID: lockWagon, SeatID: lockSeat + 100}] {
+		t.Fatal("a seat that was never locked is reported locked")
+	}
+}
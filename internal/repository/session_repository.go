@@ -3,18 +3,24 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"railway-go/internal/constant/model"
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 )
 
+const registeredGuestSessionKey = "registered_guest_session:%s"
+
 type SessionRepository interface {
 	CreateSession(ctx context.Context, session *model.Session) error
 	GetSessionByID(ctx context.Context, id string) (*model.Session, error)
 	DeleteSession(ctx context.Context, id string) error
 	GetSessionByRefreshToken(ctx context.Context, refreshToken string) (*model.Session, error)
 	UpdateSessionAccessToken(ctx context.Context, sessionID string, newAccessToken string, newExpiresAt time.Time) error
+	SaveRegisteredGuestSession(ctx context.Context, userID uuid.UUID, guestSessionID string, ttl time.Duration) error
+	GetRegisteredGuestSession(ctx context.Context, userID uuid.UUID) (string, error)
 }
 
 func (r *redisRepository) CreateSession(ctx context.Context, session *model.Session) error {
@@ -68,3 +74,18 @@ func (r *redisRepository) UpdateSessionAccessToken(ctx context.Context, sessionI
 	}
 	return r.RedisClient.HMSet(ctx, sessionKey, updateData).Err()
 }
+
+// SaveRegisteredGuestSession remembers the guest session merged into a new account until the
+// user signs in, for the ttl the state of the guest session lives.
+func (r *redisRepository) SaveRegisteredGuestSession(ctx context.Context, userID uuid.UUID, guestSessionID string, ttl time.Duration) error {
+	return r.RedisClient.Set(ctx, fmt.Sprintf(registeredGuestSessionKey, userID), guestSessionID, ttl).Err()
+}
+
+// GetRegisteredGuestSession returns the guest session merged when the user registered, empty when there is none.
+func (r *redisRepository) GetRegisteredGuestSession(ctx context.Context, userID uuid.UUID) (string, error) {
+	guestSessionID, err := r.RedisClient.Get(ctx, fmt.Sprintf(registeredGuestSessionKey, userID)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return guestSessionID, err
+}
@@ -42,18 +42,6 @@ func (q *Queries) CreateWagon(ctx context.Context, arg CreateWagonParams) (Wagon
 	return i, err
 }
 
-const decreaseWagonSeat = `-- name: DecreaseWagonSeat :exec
-UPDATE wagons
-SET total_seats = total_seats - 1,
-updated_at = NOW()
-WHERE id = $1 AND total_seats > 0
-`
-
-func (q *Queries) DecreaseWagonSeat(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, decreaseWagonSeat, id)
-	return err
-}
-
 const deleteWagon = `-- name: DeleteWagon :exec
 DELETE FROM wagons
 WHERE id = $1
@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"railway-go/internal/constant/model"
+	"railway-go/internal/repository"
+	"railway-go/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ownsPassenger reports whether the session owns the passenger: signed in users own the
+// passengers linked to their account, guests the passengers created in their session.
+func ownsPassenger(session *model.Session, passenger repository.Passenger) bool {
+	if session.UserID != nil && passenger.UserID.Valid && uuid.UUID(passenger.UserID.Bytes) == *session.UserID {
+		return true
+	}
+	return passenger.SessionID != nil && *passenger.SessionID == session.ID
+}
+
+// ownsSessionState reports whether state kept for the owner session, such as a seat hold or a
+// quote, belongs to the session: it was made by the session or by the guest session it merged.
+func ownsSessionState(ctx context.Context, owner, sessionID string) bool {
+	if owner == "" {
+		return false
+	}
+	if owner == sessionID {
+		return true
+	}
+	session, ok := utils.SessionFrom(ctx)
+	return ok && session.ID == sessionID && session.GuestSessionID == owner
+}
+
+// authorizePassenger makes sure the session of ctx may act for the passenger. Admins, general
+// affairs and background jobs may act for anyone, every refused attempt is logged.
+func (uc *UseCase) authorizePassenger(ctx context.Context, passenger repository.Passenger) error {
+	session, ok := utils.SessionFrom(ctx)
+	if !ok || session.Privileged() || ownsPassenger(session, passenger) {
+		return nil
+	}
+
+	uc.Log.Warn("forbidden passenger access", zap.String("actor", session.Actor()), zap.String("passenger_id", passenger.ID.String()))
+	return fiber.NewError(fiber.StatusForbidden, "passenger belongs to another account")
+}
+
+// authorizePassengerID loads the passenger and checks it with authorizePassenger.
+func (uc *UseCase) authorizePassengerID(ctx context.Context, tx repository.Querier, id uuid.UUID) (repository.Passenger, error) {
+	passenger, err := tx.GetPassenger(ctx, id)
+	if err != nil {
+		return repository.Passenger{}, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed to get passenger")
+	}
+	if err := uc.authorizePassenger(ctx, passenger); err != nil {
+		return repository.Passenger{}, err
+	}
+	return passenger, nil
+}
+
+// authorizeReservation makes sure the session of ctx owns the passenger of the reservation,
+// or was granted access to its booking with a verification code.
+func (uc *UseCase) authorizeReservation(ctx context.Context, tx repository.Querier, reservation repository.Reservation) error {
+	session, ok := utils.SessionFrom(ctx)
+	if !ok || session.Privileged() || uc.hasBookingAccess(ctx, session, reservation.OrderID) {
+		return nil
+	}
+
+	passenger, err := tx.GetPassenger(ctx, reservation.PassengerID)
+	if err != nil {
+		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to get reservation passenger")
+	}
+	if ownsPassenger(session, passenger) {
+		return nil
+	}
+
+	uc.Log.Warn("forbidden reservation access", zap.String("actor", session.Actor()), zap.String("reservation_id", reservation.ID.String()))
+	return fiber.NewError(fiber.StatusForbidden, "reservation belongs to another account")
+}
+
+// authorizeOrder makes sure the session of ctx may act on every reservation of the order. An
+// order without reservations has no passenger to own, only access to its booking lets it through.
+func (uc *UseCase) authorizeOrder(ctx context.Context, tx repository.Querier, order repository.Order, reservations []repository.Reservation) error {
+	session, ok := utils.SessionFrom(ctx)
+	if !ok || session.Privileged() || uc.hasBookingAccess(ctx, session, order.ID) {
+		return nil
+	}
+	if len(reservations) == 0 {
+		uc.Log.Warn("forbidden order access", zap.String("actor", session.Actor()), zap.String("booking_code", order.BookingCode))
+		return fiber.NewError(fiber.StatusForbidden, "booking belongs to another account")
+	}
+
+	for _, reservation := range reservations {
+		passenger, err := tx.GetPassenger(ctx, reservation.PassengerID)
+		if err != nil {
+			return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to get reservation passenger")
+		}
+		if !ownsPassenger(session, passenger) {
+			uc.Log.Warn("forbidden order access", zap.String("actor", session.Actor()), zap.String("booking_code", order.BookingCode))
+			return fiber.NewError(fiber.StatusForbidden, "booking belongs to another account")
+		}
+	}
+	return nil
+}
+
+// hasBookingAccess reports whether the session, or the guest session it merged, was granted
+// access to the order, a failed lookup only denies the grant.
+func (uc *UseCase) hasBookingAccess(ctx context.Context, session *model.Session, orderID uuid.UUID) bool {
+	for _, sessionID := range []string{session.ID, session.GuestSessionID} {
+		if sessionID == "" {
+			continue
+		}
+		granted, err := uc.Repo.HasBookingAccess(ctx, sessionID, orderID)
+		if err != nil {
+			uc.Log.Warn("failed to check booking access", zap.String("session_id", sessionID), zap.Error(err))
+			return false
+		}
+		if granted {
+			return true
+		}
+	}
+	return false
+}
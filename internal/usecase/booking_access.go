@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"railway-go/internal/constant/model"
+	"railway-go/internal/repository"
+	"railway-go/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// UpdateBookingContact attaches the contact of the booker to the order, guests retrieve the
+// booking with its email and booking code once their session is gone.
+func (uc *OrderUsecase) UpdateBookingContact(ctx context.Context, code string, req model.BookingContactRequest) (response model.BookingContactResponse, err error) {
+	if err := uc.Validate.Struct(req); err != nil {
+		return model.BookingContactResponse{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "validation failed")
+	}
+
+	tx, err := uc.Repo.BeginTransaction(ctx)
+	if err != nil {
+		return model.BookingContactResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to begin transaction")
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	order, err := tx.GetOrderByCode(ctx, strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return model.BookingContactResponse{}, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed to get order")
+	}
+	reservations, err := tx.ListOrderReservations(ctx, order.ID)
+	if err != nil {
+		return model.BookingContactResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to get order reservations")
+	}
+	if err = uc.authorizeOrder(ctx, tx, order, reservations); err != nil {
+		return model.BookingContactResponse{}, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	order, err = tx.UpdateOrderContact(ctx, repository.UpdateOrderContactParams{
+		ID:           order.ID,
+		ContactEmail: &email,
+		ContactPhone: optional(strings.TrimSpace(req.Phone)),
+	})
+	if err != nil {
+		return model.BookingContactResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to update booking contact")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return model.BookingContactResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to commit transaction")
+	}
+
+	return model.BookingContactResponse{
+		BookingCode: order.BookingCode,
+		Email:       deref(order.ContactEmail),
+		Phone:       deref(order.ContactPhone),
+	}, nil
+}
+
+// RequestBookingAccess sends a one-time verification code to the contact email of the booking.
+// An unknown booking code or email gets the same answer, so bookings cannot be probed, the
+// cooldown between requests holds for every booking code and email whether they match or not.
+func (uc *OrderUsecase) RequestBookingAccess(ctx context.Context, req model.BookingAccessRequest) error {
+	if err := uc.Validate.Struct(req); err != nil {
+		return utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "validation failed")
+	}
+	code := strings.ToUpper(strings.TrimSpace(req.BookingCode))
+
+	started, err := uc.Repo.StartVerificationCooldown(ctx, code, strings.ToLower(strings.TrimSpace(req.Email)), model.BookingVerificationCooldown)
+	if err != nil {
+		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to start verification cooldown")
+	}
+	if !started {
+		return fiber.NewError(fiber.StatusTooManyRequests, "a verification code was requested recently, please wait before requesting another")
+	}
+
+	order, err := uc.Repo.GetOrderByCode(ctx, code)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to get order")
+	}
+	if err != nil || !sameContact(order.ContactEmail, req.Email) {
+		uc.Log.Warn("booking access requested for an unknown contact", zap.String("booking_code", code))
+		return nil
+	}
+
+	// past the limit no code is sent, answered like an unknown contact so the limit gives nothing away
+	sent, err := uc.Repo.CountVerificationSent(ctx, code, model.BookingVerificationWindow)
+	if err != nil {
+		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to count verification codes")
+	}
+	if sent > model.BookingVerificationSends {
+		uc.Log.Warn("booking verification codes used up", zap.String("booking_code", code), zap.Int64("sent", sent))
+		return nil
+	}
+
+	verificationCode, err := utils.GenerateVerificationCode()
+	if err != nil {
+		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to generate verification code")
+	}
+	if err := uc.Repo.SaveBookingVerification(ctx, code, model.BookingVerification{
+		CodeHash: utils.HashVerificationCode(verificationCode),
+		SentAt:   time.Now(),
+	}, model.BookingVerificationTTL); err != nil {
+		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to save verification code")
+	}
+
+	message := fmt.Sprintf("Your verification code for booking %s is %s, it expires in %d minutes.", code, verificationCode, int(model.BookingVerificationTTL.Minutes()))
+	if err := uc.notifier.Send(ctx, *order.ContactEmail, "Your booking verification code", message); err != nil {
+		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to send verification code")
+	}
+	return nil
+}
+
+// VerifyBookingAccess checks the code sent for the booking and grants the session access to
+// it until the session expires, so it can be paid, downloaded or cancelled like an own booking.
+// A code is used once and dropped once the attempts of the booking are used up. Attempts are
+// counted per booking over model.BookingVerificationWindow, across every code sent for it, and
+// before the code is compared so concurrent guesses cannot exceed the limit.
+func (uc *OrderUsecase) VerifyBookingAccess(ctx context.Context, req model.BookingVerifyRequest) (model.OrderDetailResponse, error) {
+	if err := uc.Validate.Struct(req); err != nil {
+		return model.OrderDetailResponse{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "validation failed")
+	}
+	session, ok := utils.SessionFrom(ctx)
+	if !ok {
+		return model.OrderDetailResponse{}, fiber.NewError(fiber.StatusUnauthorized, "session is required")
+	}
+	code := strings.ToUpper(strings.TrimSpace(req.BookingCode))
+	invalid := fiber.NewError(fiber.StatusBadRequest, "verification code is invalid or expired")
+
+	verification, err := uc.Repo.GetBookingVerification(ctx, code)
+	if errors.Is(err, model.ErrVerificationNotFound) {
+		return model.OrderDetailResponse{}, invalid
+	}
+	if err != nil {
+		return model.OrderDetailResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to get verification code")
+	}
+
+	attempts, err := uc.Repo.CountVerificationAttempt(ctx, code, model.BookingVerificationWindow)
+	if err != nil {
+		return model.OrderDetailResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to record verification attempt")
+	}
+	if attempts > model.BookingVerificationAttempts {
+		uc.Log.Warn("booking verification attempts used up", zap.String("booking_code", code), zap.String("session_id", session.ID))
+		return model.OrderDetailResponse{}, invalid
+	}
+
+	if !utils.VerificationCodeMatches(req.Code, verification.CodeHash) {
+		if attempts == model.BookingVerificationAttempts {
+			if err := uc.Repo.DeleteBookingVerification(ctx, code); err != nil {
+				uc.Log.Warn("failed to drop verification code", zap.String("booking_code", code), zap.Error(err))
+			}
+		}
+		uc.Log.Warn("wrong booking verification code", zap.String("booking_code", code), zap.String("session_id", session.ID), zap.Int64("attempts", attempts))
+		return model.OrderDetailResponse{}, invalid
+	}
+
+	order, err := uc.Repo.GetOrderByCode(ctx, code)
+	if err != nil {
+		return model.OrderDetailResponse{}, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed to get order")
+	}
+	if !sameContact(order.ContactEmail, req.Email) {
+		return model.OrderDetailResponse{}, invalid
+	}
+
+	if err := uc.Repo.DeleteBookingVerification(ctx, code); err != nil {
+		return model.OrderDetailResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to use verification code")
+	}
+	if err := uc.Repo.GrantBookingAccess(ctx, session.ID, order.ID, time.Until(session.ExpiresAt)); err != nil {
+		return model.OrderDetailResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to grant booking access")
+	}
+
+	uc.Log.Info("booking access granted", zap.String("booking_code", code), zap.String("session_id", session.ID))
+	return uc.GetOrderByCode(ctx, code)
+}
+
+// sameContact reports whether the email is the contact email of a booking.
+func sameContact(contact *string, email string) bool {
+	return contact != nil && strings.EqualFold(*contact, strings.TrimSpace(email))
+}
@@ -152,6 +152,10 @@ func (uc *DiscountUsecase) ApplyDiscountToReservation(ctx context.Context, reser
 		return utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed to get reservation")
 	}
 
+	if err = uc.authorizeReservation(ctx, tx, reservation); err != nil {
+		return err
+	}
+
 	if reservation.DiscountID.Valid {
 		return utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "discount code already applied")
 	}
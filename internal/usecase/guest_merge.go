@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"context"
+	"railway-go/internal/constant/model"
+	"railway-go/internal/repository"
+	"railway-go/internal/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// guestSession loads the guest session a client browsed with before signing in. It is nil
+// when there is none, or when the cookie names a session that is not a live guest session.
+func (uc *UserSessionUsecase) guestSession(ctx context.Context, id string) *model.Session {
+	if id == "" {
+		return nil
+	}
+	session, err := uc.Repo.GetSessionByID(ctx, id)
+	if err != nil || session.Role != "guest" || session.UserID != nil || session.ExpiresAt.Before(time.Now()) {
+		return nil
+	}
+	return session
+}
+
+// mergeGuestBookings moves the passengers created in the guest session to the user. The
+// reservations of those passengers, pending ones included, belong to the user with them.
+func (uc *UserSessionUsecase) mergeGuestBookings(ctx context.Context, tx repository.Querier, guest *model.Session, userID uuid.UUID) error {
+	if guest == nil {
+		return nil
+	}
+
+	merged, err := tx.MergeGuestPassengers(ctx, repository.MergeGuestPassengersParams{
+		UserID:    utils.ToPgUUID(userID),
+		SessionID: &guest.ID,
+	})
+	if err != nil {
+		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to merge guest bookings")
+	}
+
+	if len(merged) > 0 {
+		uc.Log.Info("guest bookings merged", zap.String("session_id", guest.ID), zap.String("user_id", userID.String()), zap.Int("passengers", len(merged)))
+	}
+	return nil
+}
+
+// retireGuestSession deletes a merged guest session, so it no longer acts for the passengers
+// it created. Its holds, seat locks, quotes and booking access stay keyed by its ID and are
+// used by the session that merged it, see model.Session.GuestSessionID.
+func (uc *UserSessionUsecase) retireGuestSession(ctx context.Context, guest *model.Session) {
+	if guest == nil {
+		return
+	}
+	if err := uc.Repo.DeleteSession(ctx, guest.ID); err != nil {
+		uc.Log.Warn("failed to retire guest session", zap.String("session_id", guest.ID), zap.Error(err))
+	}
+}
+
+// rememberGuestSession keeps the guest session merged into a new account for its first login,
+// which carries the holds, quotes and booking access of the guest over like a login from it.
+func (uc *UserSessionUsecase) rememberGuestSession(ctx context.Context, guest *model.Session, userID uuid.UUID) {
+	if guest == nil {
+		return
+	}
+	if err := uc.Repo.SaveRegisteredGuestSession(ctx, userID, guest.ID, time.Until(guest.ExpiresAt)); err != nil {
+		uc.Log.Warn("failed to keep the registered guest session", zap.String("session_id", guest.ID), zap.Error(err))
+	}
+}
+
+// registeredGuestSession is the guest session merged when the user registered, a failed lookup carries nothing over.
+func (uc *UserSessionUsecase) registeredGuestSession(ctx context.Context, userID uuid.UUID) string {
+	guestSessionID, err := uc.Repo.GetRegisteredGuestSession(ctx, userID)
+	if err != nil {
+		uc.Log.Warn("failed to get the registered guest session", zap.String("user_id", userID.String()), zap.Error(err))
+		return ""
+	}
+	return guestSessionID
+}
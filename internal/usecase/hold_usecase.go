@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"railway-go/internal/constant/model"
+	"railway-go/internal/repository"
+	"railway-go/internal/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type HoldUC interface {
+	CreateHold(ctx context.Context, req model.HoldRequest) (model.HoldResponse, error)
+	ExtendHold(ctx context.Context, id, sessionID string) (model.HoldResponse, error)
+	ReleaseHold(ctx context.Context, id, sessionID string) error
+}
+
+type HoldUsecase struct {
+	*UseCase
+}
+
+func NewHoldUsecase(useCase *UseCase) HoldUC {
+	return &HoldUsecase{UseCase: useCase}
+}
+
+// CreateHold locks a free seat for the calling session for model.HoldTTL. The hold can be
+// extended, released, or passed as hold_id when creating a reservation for the same seat.
+func (uc *HoldUsecase) CreateHold(ctx context.Context, req model.HoldRequest) (response model.HoldResponse, err error) {
+	if err := uc.Validate.Struct(req); err != nil {
+		return model.HoldResponse{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "validation failed")
+	}
+	if req.SessionID == "" {
+		return model.HoldResponse{}, fiber.NewError(fiber.StatusUnauthorized, "session id is required")
+	}
+
+	tx, err := uc.Repo.BeginTransaction(ctx)
+	if err != nil {
+		return model.HoldResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to begin transaction")
+	}
+	// the transaction only reads, it is never committed
+	defer tx.Rollback(ctx)
+
+	schedule, wagon, seat, err := uc.scheduleSeat(ctx, tx, req.ScheduleID, req.WagonID, req.SeatID)
+	if err != nil {
+		return model.HoldResponse{}, err
+	}
+
+	segment, err := uc.resolveSegment(ctx, tx, schedule, req.FromStation, req.ToStation)
+	if err != nil {
+		return model.HoldResponse{}, err
+	}
+
+	booked, err := tx.CheckSeatAvailability(ctx, repository.CheckSeatAvailabilityParams{
+		ScheduleID: schedule.ID,
+		WagonID:    wagon.ID,
+		SeatID:     seat.ID,
+		FromStop:   segment.From,
+		ToStop:     segment.To,
+	})
+	if err != nil {
+		return model.HoldResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to check seat availability")
+	}
+	if booked > 0 {
+		return model.HoldResponse{}, fiber.NewError(fiber.StatusConflict, "seat already booked")
+	}
+
+	if err := uc.Repo.LockSeat(ctx, schedule.ID, wagon.ID, seat.ID, req.SessionID, model.HoldTTL); err != nil {
+		return model.HoldResponse{}, utils.WrapError(fiber.StatusConflict, uc.Log, utils.Warn, err, "seat is already held")
+	}
+
+	now := time.Now()
+	hold := model.SeatHold{
+		ID:         uuid.NewString(),
+		SessionID:  req.SessionID,
+		ScheduleID: schedule.ID,
+		WagonID:    wagon.ID,
+		SeatID:     seat.ID,
+		FromStop:   segment.From,
+		ToStop:     segment.To,
+		ExpiresAt:  now.Add(model.HoldTTL),
+		CreatedAt:  now,
+	}
+	if err := uc.Repo.SaveSeatHold(ctx, hold); err != nil {
+		if unlockErr := uc.Repo.UnlockSeat(ctx, schedule.ID, wagon.ID, seat.ID, req.SessionID); unlockErr != nil {
+			uc.Log.Warn("failed to unlock seat", zap.Int64("seat_id", seat.ID), zap.Error(unlockErr))
+		}
+		return model.HoldResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to save seat hold")
+	}
+
+	uc.Log.Info("seat held", zap.String("hold_id", hold.ID), zap.Int64("schedule_id", schedule.ID), zap.Int64("seat_id", seat.ID))
+	return toHoldResponse(hold), nil
+}
+
+// ExtendHold pushes the expiry of a hold another model.HoldTTL ahead, never past
+// model.HoldMaxLifetime after the hold was created.
+func (uc *HoldUsecase) ExtendHold(ctx context.Context, id, sessionID string) (model.HoldResponse, error) {
+	hold, err := uc.ownedHold(ctx, id, sessionID)
+	if err != nil {
+		return model.HoldResponse{}, err
+	}
+
+	expiresAt := time.Now().Add(model.HoldTTL)
+	if limit := hold.CreatedAt.Add(model.HoldMaxLifetime); expiresAt.After(limit) {
+		expiresAt = limit
+	}
+	if !expiresAt.After(hold.ExpiresAt) {
+		return model.HoldResponse{}, fiber.NewError(fiber.StatusConflict, "hold has reached its maximum lifetime")
+	}
+
+	if err := uc.Repo.ExtendSeatLock(ctx, hold.ScheduleID, hold.WagonID, hold.SeatID, hold.SessionID, time.Until(expiresAt)); err != nil {
+		if errors.Is(err, model.ErrSeatNotLocked) {
+			return model.HoldResponse{}, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, model.ErrHoldNotFound, model.ErrHoldNotFound.Error())
+		}
+		return model.HoldResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to extend seat lock")
+	}
+
+	hold.ExpiresAt = expiresAt
+	if err := uc.Repo.SaveSeatHold(ctx, hold); err != nil {
+		return model.HoldResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to save seat hold")
+	}
+
+	return toHoldResponse(hold), nil
+}
+
+func (uc *HoldUsecase) ReleaseHold(ctx context.Context, id, sessionID string) error {
+	hold, err := uc.ownedHold(ctx, id, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.Repo.UnlockSeat(ctx, hold.ScheduleID, hold.WagonID, hold.SeatID, hold.SessionID); err != nil && !errors.Is(err, model.ErrSeatNotLocked) {
+		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to unlock seat")
+	}
+	if err := uc.Repo.DeleteSeatHold(ctx, hold.ID); err != nil {
+		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to delete seat hold")
+	}
+
+	uc.Log.Info("seat hold released", zap.String("hold_id", hold.ID))
+	return nil
+}
+
+// scheduleSeat loads a seat of a schedule, making sure the wagon runs on the schedule's train,
+// the seat is part of that wagon and the seat is in service.
+func (uc *UseCase) scheduleSeat(ctx context.Context, tx repository.Querier, scheduleID, wagonID, seatID int64) (repository.Schedule, repository.Wagon, repository.Seat, error) {
+	schedule, err := tx.GetSchedule(ctx, scheduleID)
+	if err != nil {
+		return repository.Schedule{}, repository.Wagon{}, repository.Seat{}, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed fetch schedule")
+	}
+
+	wagon, err := tx.GetWagon(ctx, wagonID)
+	if err != nil || wagon.TrainID != schedule.TrainID {
+		return repository.Schedule{}, repository.Wagon{}, repository.Seat{}, fiber.NewError(fiber.StatusBadRequest, "wagon does not belong to the schedule's train")
+	}
+
+	seat, err := tx.GetSeat(ctx, seatID)
+	if err != nil || seat.WagonID == nil || *seat.WagonID != wagon.ID {
+		return repository.Schedule{}, repository.Wagon{}, repository.Seat{}, fiber.NewError(fiber.StatusBadRequest, "seat does not belong to the selected wagon")
+	}
+	if seat.IsAvailable != nil && !*seat.IsAvailable {
+		return repository.Schedule{}, repository.Wagon{}, repository.Seat{}, fiber.NewError(fiber.StatusConflict, "seat is out of service")
+	}
+
+	return schedule, wagon, seat, nil
+}
+
+// releaseBookingLock drops the lock a booking took on its seat once the reservation is
+// committed. From then on the reservation's stop range keeps overlapping bookings out, so
+// keeping the lock would only block the other segments of the seat.
+func (uc *UseCase) releaseBookingLock(ctx context.Context, scheduleID, wagonID, seatID int64, owner string) {
+	if err := uc.Repo.UnlockSeat(ctx, scheduleID, wagonID, seatID, owner); err != nil && !errors.Is(err, model.ErrSeatNotLocked) {
+		uc.Log.Warn("failed to unlock seat", zap.Int64("seat_id", seatID), zap.Error(err))
+	}
+}
+
+// ownedHold loads a hold that is still alive and belongs to the given session, see ownsSessionState.
+func (uc *UseCase) ownedHold(ctx context.Context, id, sessionID string) (model.SeatHold, error) {
+	hold, err := uc.Repo.GetSeatHold(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrHoldNotFound) {
+			return model.SeatHold{}, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, err.Error())
+		}
+		return model.SeatHold{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to get seat hold")
+	}
+
+	if !ownsSessionState(ctx, hold.SessionID, sessionID) {
+		return model.SeatHold{}, fiber.NewError(fiber.StatusForbidden, "seat hold belongs to another session")
+	}
+	return hold, nil
+}
+
+func toHoldResponse(hold model.SeatHold) model.HoldResponse {
+	return model.HoldResponse{
+		HoldID:     hold.ID,
+		ScheduleID: hold.ScheduleID,
+		WagonID:    hold.WagonID,
+		SeatID:     hold.SeatID,
+		FromStop:   hold.FromStop,
+		ToStop:     hold.ToStop,
+		ExpiresAt:  hold.ExpiresAt,
+		CreatedAt:  hold.CreatedAt,
+	}
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"railway-go/internal/constant/model"
+	"railway-go/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type IdempotencyUC interface {
+	BeginRequest(ctx context.Context, key string) (model.IdempotencyRecord, bool, error)
+	CompleteRequest(ctx context.Context, key string, record model.IdempotencyRecord) error
+	AbandonRequest(ctx context.Context, key string)
+}
+
+type IdempotencyUsecase struct {
+	*UseCase
+}
+
+func NewIdempotencyUsecase(useCase *UseCase) IdempotencyUC {
+	return &IdempotencyUsecase{UseCase: useCase}
+}
+
+// BeginRequest claims the key for a new request. When the key was already used the stored
+// record is returned for replay, while a request still in flight under the key is a conflict.
+func (uc *IdempotencyUsecase) BeginRequest(ctx context.Context, key string) (model.IdempotencyRecord, bool, error) {
+	claimed, err := uc.Repo.ClaimIdempotencyKey(ctx, key, model.IdempotencyLockTTL)
+	if err != nil {
+		return model.IdempotencyRecord{}, false, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to claim idempotency key")
+	}
+	if claimed {
+		return model.IdempotencyRecord{}, false, nil
+	}
+
+	record, err := uc.Repo.GetIdempotencyRecord(ctx, key)
+	if err != nil {
+		// the claim expired in between, the client may simply retry
+		if errors.Is(err, model.ErrIdempotencyKeyNotFound) {
+			return model.IdempotencyRecord{}, false, fiber.NewError(fiber.StatusConflict, "request with this idempotency key is being processed, please retry")
+		}
+		return model.IdempotencyRecord{}, false, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to get idempotency record")
+	}
+	if !record.Completed {
+		return model.IdempotencyRecord{}, false, fiber.NewError(fiber.StatusConflict, "request with this idempotency key is being processed, please retry")
+	}
+
+	return record, true, nil
+}
+
+// CompleteRequest stores the response of a claimed request for model.IdempotencyTTL.
+func (uc *IdempotencyUsecase) CompleteRequest(ctx context.Context, key string, record model.IdempotencyRecord) error {
+	record.Completed = true
+	if err := uc.Repo.SaveIdempotencyRecord(ctx, key, record, model.IdempotencyTTL); err != nil {
+		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to save idempotency record")
+	}
+	return nil
+}
+
+// AbandonRequest frees the key of a request that failed, so a retry is processed again.
+func (uc *IdempotencyUsecase) AbandonRequest(ctx context.Context, key string) {
+	if err := uc.Repo.DeleteIdempotencyRecord(ctx, key); err != nil {
+		uc.Log.Warn("failed to release idempotency key", zap.Error(err))
+	}
+}
@@ -0,0 +1,319 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"railway-go/internal/constant/model"
+	"railway-go/internal/repository"
+	"railway-go/internal/utils"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+type JourneyUC interface {
+	SearchJourneys(ctx context.Context, req model.JourneySearchRequest) ([]model.JourneyResponse, error)
+}
+
+type JourneyUsecase struct {
+	*UseCase
+	config model.JourneyConfig
+}
+
+// NewJourneyUsecase reads the planner settings from the journey section, every missing or
+// invalid setting falls back to model.DefaultJourneyConfig.
+func NewJourneyUsecase(useCase *UseCase, config *viper.Viper) JourneyUC {
+	journey := model.DefaultJourneyConfig
+	if err := config.UnmarshalKey("journey", &journey); err != nil {
+		useCase.Log.Warn("invalid journey planner settings, using the defaults", zap.Error(err))
+		journey = model.DefaultJourneyConfig
+	}
+
+	if journey.MinConnectionMinutes < 0 {
+		journey.MinConnectionMinutes = model.DefaultJourneyConfig.MinConnectionMinutes
+	}
+	if journey.MaxTransfers < 0 {
+		journey.MaxTransfers = model.DefaultJourneyConfig.MaxTransfers
+	}
+	if journey.MaxDurationHours <= 0 {
+		journey.MaxDurationHours = model.DefaultJourneyConfig.MaxDurationHours
+	}
+	if journey.MaxResults <= 0 {
+		journey.MaxResults = model.DefaultJourneyConfig.MaxResults
+	}
+
+	// station codes are matched upper case, viper lowers map keys
+	connections := make(map[string]int, len(journey.ConnectionMinutes))
+	for code, minutes := range journey.ConnectionMinutes {
+		connections[strings.ToUpper(code)] = minutes
+	}
+	journey.ConnectionMinutes = connections
+
+	return &JourneyUsecase{UseCase: useCase, config: journey}
+}
+
+// journeyHop is a ride on one schedule between two of its stops.
+type journeyHop struct {
+	Schedule  repository.ListDepartingSchedulesRow
+	From      string
+	To        string
+	Departure time.Time
+	Arrival   time.Time
+	Price     int64
+}
+
+// SearchJourneys plans journeys between two stations departing on the given date, direct or
+// with transfers between schedules of any route. A transfer needs the connection time of the
+// station it happens at. Journeys are ranked by total duration, then price, then transfers,
+// and every leg can be booked with its schedule id and stations.
+func (uc *JourneyUsecase) SearchJourneys(ctx context.Context, req model.JourneySearchRequest) ([]model.JourneyResponse, error) {
+	req.FromStation = strings.ToUpper(strings.TrimSpace(req.FromStation))
+	req.ToStation = strings.ToUpper(strings.TrimSpace(req.ToStation))
+	if err := uc.Validate.Struct(req); err != nil {
+		return nil, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "validation failed")
+	}
+
+	day, err := time.Parse("2006-01-02", req.DepartureDate)
+	if err != nil {
+		return nil, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "failed to convert departuredate")
+	}
+	if day.Before(time.Now().Truncate(24 * time.Hour)) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "departure date cannot be in the past")
+	}
+
+	for _, code := range []string{req.FromStation, req.ToStation} {
+		if _, err := uc.Repo.GetStationByCode(ctx, code); err != nil {
+			return nil, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, fmt.Sprintf("station %s not found", code))
+		}
+	}
+
+	// a schedule that left the day before may still pass the origin on the date,
+	// and connections may run past the date up to the longest journey allowed
+	maxDuration := time.Duration(uc.config.MaxDurationHours) * time.Hour
+	dayEnd := day.Add(24 * time.Hour)
+	schedules, err := uc.Repo.ListDepartingSchedules(ctx, repository.ListDepartingSchedulesParams{
+		WindowStart: pgtype.Timestamp{Time: day.Add(-maxDuration), Valid: true},
+		WindowEnd:   pgtype.Timestamp{Time: dayEnd.Add(maxDuration), Valid: true},
+	})
+	if err != nil {
+		return nil, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to list schedules")
+	}
+
+	hops, err := uc.journeyHops(ctx, schedules, repository.TipeClass(req.ClassType))
+	if err != nil {
+		return nil, err
+	}
+
+	earliest := time.Now()
+	if day.After(earliest) {
+		earliest = day
+	}
+
+	var journeys [][]journeyHop
+	visited := map[string]bool{req.FromStation: true}
+	var walk func(station string, ready time.Time, legs []journeyHop)
+	walk = func(station string, ready time.Time, legs []journeyHop) {
+		for _, hop := range hops[station] {
+			if hop.Departure.Before(ready) || visited[hop.To] {
+				continue
+			}
+			if len(legs) == 0 && !hop.Departure.Before(dayEnd) {
+				continue
+			}
+			// staying on board is already covered by the longer ride on the same schedule
+			if onSchedule(legs, hop.Schedule.ID) {
+				continue
+			}
+			start := hop.Departure
+			if len(legs) > 0 {
+				start = legs[0].Departure
+			}
+			if hop.Arrival.Sub(start) > maxDuration {
+				continue
+			}
+
+			next := append(legs[:len(legs):len(legs)], hop)
+			if hop.To == req.ToStation {
+				journeys = append(journeys, next)
+				continue
+			}
+			if len(next) > uc.config.MaxTransfers {
+				continue
+			}
+
+			visited[hop.To] = true
+			walk(hop.To, hop.Arrival.Add(uc.connectionTime(hop.To)), next)
+			visited[hop.To] = false
+		}
+	}
+	walk(req.FromStation, earliest, nil)
+
+	response := make([]model.JourneyResponse, 0, len(journeys))
+	for _, journey := range journeys {
+		response = append(response, toJourneyResponse(journey))
+	}
+	sort.SliceStable(response, func(i, j int) bool {
+		if response[i].DurationMinutes != response[j].DurationMinutes {
+			return response[i].DurationMinutes < response[j].DurationMinutes
+		}
+		if response[i].TotalPrice != response[j].TotalPrice {
+			return response[i].TotalPrice < response[j].TotalPrice
+		}
+		return response[i].Transfers < response[j].Transfers
+	})
+	if len(response) > uc.config.MaxResults {
+		response = response[:uc.config.MaxResults]
+	}
+
+	return response, nil
+}
+
+// journeyHops lists every ride between two stops of the schedules that has a seat left for the
+// stops, keyed by boarding station and ordered by departure. Seats are counted from pending and
+// paid reservations like the schedule search, seat locks are left out. Rides are priced like a
+// booking of the same stops in the class, or in the cheapest class with a seat left, see hopFare.
+func (uc *JourneyUsecase) journeyHops(ctx context.Context, schedules []repository.ListDepartingSchedulesRow, class repository.TipeClass) (map[string][]journeyHop, error) {
+	hops := make(map[string][]journeyHop)
+	if len(schedules) == 0 {
+		return hops, nil
+	}
+
+	scheduleIDs := make([]int64, 0, len(schedules))
+	routeIDs := make([]int64, 0, len(schedules))
+	for _, schedule := range schedules {
+		scheduleIDs = append(scheduleIDs, schedule.ID)
+		routeIDs = append(routeIDs, schedule.RouteID)
+	}
+
+	routeStops, err := uc.Repo.ListRouteStopsByRoutes(ctx, routeIDs)
+	if err != nil {
+		return nil, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to list route stops")
+	}
+	stopsByRoute := make(map[int64][]repository.RouteStop)
+	for _, stop := range routeStops {
+		stopsByRoute[stop.RouteID] = append(stopsByRoute[stop.RouteID], stop)
+	}
+
+	bookedLegs, err := uc.Repo.ListScheduleBookedLegs(ctx, scheduleIDs)
+	if err != nil {
+		return nil, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to count available seats")
+	}
+	seats := make(map[int64][]repository.ListScheduleBookedLegsRow)
+	for _, row := range bookedLegs {
+		if class == "" || row.ClassType == class {
+			seats[row.ScheduleID] = append(seats[row.ScheduleID], row)
+		}
+	}
+
+	for _, schedule := range schedules {
+		stops := toRouteStops(repository.Route{
+			ID:                 schedule.RouteID,
+			SourceStation:      schedule.SourceStation,
+			DestinationStation: schedule.DestinationStation,
+			TravelTime:         schedule.TravelTime,
+		}, stopsByRoute[schedule.RouteID])
+
+		legs := int32(len(stops) - 1)
+		for i := 0; i < len(stops)-1; i++ {
+			for j := i + 1; j < len(stops); j++ {
+				segment := journeySegment{From: int32(i), To: int32(j), Legs: legs}
+				classes := freeClasses(seats[schedule.ID], segment)
+				if len(classes) == 0 {
+					continue
+				}
+				hops[stops[i].StationCode] = append(hops[stops[i].StationCode], journeyHop{
+					Schedule:  schedule,
+					From:      stops[i].StationCode,
+					To:        stops[j].StationCode,
+					Departure: schedule.DepartureDate.Time.Add(time.Duration(stops[i].MinutesFromStart) * time.Minute),
+					Arrival:   schedule.DepartureDate.Time.Add(time.Duration(stops[j].MinutesFromStart) * time.Minute),
+					Price:     uc.hopFare(schedule, segment, classes),
+				})
+			}
+		}
+	}
+
+	for station := range hops {
+		sort.SliceStable(hops[station], func(i, j int) bool {
+			return hops[station][i].Departure.Before(hops[station][j].Departure)
+		})
+	}
+	return hops, nil
+}
+
+// freeClasses lists the classes with a seat whose reservations leave the legs of the segment free.
+func freeClasses(seats []repository.ListScheduleBookedLegsRow, segment journeySegment) []repository.TipeClass {
+	legs := (int64(1)<<(segment.To-segment.From) - 1) << segment.From
+	var classes []repository.TipeClass
+	for _, seat := range seats {
+		if seat.BookedLegs&legs == 0 && !slices.Contains(classes, seat.ClassType) {
+			classes = append(classes, seat.ClassType)
+		}
+	}
+	return classes
+}
+
+// hopFare is the total an adult pays for the ride in the cheapest of the classes, with every
+// item of the fare rules but no discount.
+func (uc *JourneyUsecase) hopFare(row repository.ListDepartingSchedulesRow, segment journeySegment, classes []repository.TipeClass) int64 {
+	schedule := repository.Schedule{
+		ID:            row.ID,
+		TrainID:       row.TrainID,
+		RouteID:       row.RouteID,
+		DepartureDate: row.DepartureDate,
+		ArrivalDate:   row.ArrivalDate,
+		Price:         row.Price,
+	}
+
+	var cheapest int64
+	for i, class := range classes {
+		if total := uc.fare(schedule, class, segment, model.DefaultPassengerType, 0).Total; i == 0 || total < cheapest {
+			cheapest = total
+		}
+	}
+	return cheapest
+}
+
+// connectionTime is the time needed to change trains at the station.
+func (uc *JourneyUsecase) connectionTime(station string) time.Duration {
+	if minutes, ok := uc.config.ConnectionMinutes[station]; ok {
+		return time.Duration(minutes) * time.Minute
+	}
+	return time.Duration(uc.config.MinConnectionMinutes) * time.Minute
+}
+
+func onSchedule(legs []journeyHop, scheduleID int64) bool {
+	for _, leg := range legs {
+		if leg.Schedule.ID == scheduleID {
+			return true
+		}
+	}
+	return false
+}
+
+func toJourneyResponse(hops []journeyHop) model.JourneyResponse {
+	response := model.JourneyResponse{
+		Legs:            make([]model.JourneyLeg, 0, len(hops)),
+		Transfers:       len(hops) - 1,
+		DurationMinutes: int64(hops[len(hops)-1].Arrival.Sub(hops[0].Departure).Minutes()),
+	}
+	for _, hop := range hops {
+		response.Legs = append(response.Legs, model.JourneyLeg{
+			ScheduleID:    hop.Schedule.ID,
+			TrainName:     hop.Schedule.TrainName,
+			FromStation:   hop.From,
+			ToStation:     hop.To,
+			DepartureDate: hop.Departure,
+			ArrivalDate:   hop.Arrival,
+			Price:         hop.Price,
+		})
+		response.TotalPrice += hop.Price
+	}
+	return response
+}
@@ -0,0 +1,505 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"railway-go/internal/constant/model"
+	"railway-go/internal/repository"
+	"railway-go/internal/utils"
+	"railway-go/internal/utils/notification"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+type OrderUC interface {
+	CreateOrder(ctx context.Context, req model.OrderRequest) (model.OrderResponse, error)
+	GetOrderByCode(ctx context.Context, code string) (model.OrderDetailResponse, error)
+	CreateAutoOrder(ctx context.Context, req model.AutoOrderRequest) (model.OrderResponse, error)
+	CreateItineraryOrder(ctx context.Context, req model.ItineraryRequest) (model.OrderResponse, error)
+	UpdateBookingContact(ctx context.Context, code string, req model.BookingContactRequest) (model.BookingContactResponse, error)
+	RequestBookingAccess(ctx context.Context, req model.BookingAccessRequest) error
+	VerifyBookingAccess(ctx context.Context, req model.BookingVerifyRequest) (model.OrderDetailResponse, error)
+}
+
+type OrderUsecase struct {
+	*UseCase
+	notifier notification.Notifier
+}
+
+func NewOrderUsecase(useCase *UseCase, notifier notification.Notifier) OrderUC {
+	return &OrderUsecase{UseCase: useCase, notifier: notifier}
+}
+
+// seatKey identifies one seat of a schedule, as used by the seat locks.
+type seatKey struct {
+	ScheduleID int64
+	WagonID    int64
+	SeatID     int64
+}
+
+// CreateOrder books several seats of one schedule for several passengers at once.
+// It is an itinerary of a single leg, see CreateItineraryOrder.
+func (uc *OrderUsecase) CreateOrder(ctx context.Context, req model.OrderRequest) (model.OrderResponse, error) {
+	if err := uc.Validate.Struct(req); err != nil {
+		return model.OrderResponse{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "validation failed")
+	}
+
+	return uc.CreateItineraryOrder(ctx, model.ItineraryRequest{
+		DiscountID: req.DiscountID,
+		Legs: []model.ItineraryLegRequest{{
+			ScheduleID:  req.ScheduleID,
+			FromStation: req.FromStation,
+			ToStation:   req.ToStation,
+			Items:       req.Items,
+		}},
+		SessionID: req.SessionID,
+	})
+}
+
+// itineraryLeg is a validated leg of an itinerary order with the fare of every seat.
+type itineraryLeg struct {
+	Schedule   repository.Schedule
+	Segment    journeySegment
+	DiscountID pgtype.UUID
+	Items      []model.OrderItemRequest
+	// Prices are aligned with Items, every seat is priced by the class of its wagon
+	// and the type of its passenger
+	Prices         []int64
+	PassengerTypes []string
+}
+
+// CreateItineraryOrder books the seats of every leg of a round trip or multi-city journey.
+// Every seat of every leg is locked first, then all reservations are created in a single
+// transaction sharing one order, one combined price and one expiry, so the whole itinerary
+// is paid at once. If any seat of any leg cannot be booked, nothing is created and every
+// lock taken so far is released. Once committed the locks are released as well, the
+// reservations keep the seats from then on.
+func (uc *OrderUsecase) CreateItineraryOrder(ctx context.Context, req model.ItineraryRequest) (response model.OrderResponse, err error) {
+	if err := uc.Validate.Struct(req); err != nil {
+		return model.OrderResponse{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "validation failed")
+	}
+
+	tx, err := uc.Repo.BeginTransaction(ctx)
+	if err != nil {
+		return model.OrderResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to begin transaction")
+	}
+
+	var locked []seatKey
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				uc.Log.Error("rollback failed", zap.Error(rollbackErr))
+			}
+			for _, key := range locked {
+				if unlockErr := uc.Repo.UnlockSeat(ctx, key.ScheduleID, key.WagonID, key.SeatID, req.SessionID); unlockErr != nil {
+					uc.Log.Warn("failed to unlock seat", zap.Any("seat", key), zap.Error(unlockErr))
+				}
+			}
+		}
+	}()
+
+	// validate every leg before touching any lock
+	legs := make([]itineraryLeg, 0, len(req.Legs))
+	for i, legReq := range req.Legs {
+		leg, err := uc.itineraryLeg(ctx, tx, req.DiscountID, legReq)
+		if err != nil {
+			return model.OrderResponse{}, err
+		}
+		// connections are checked at the stations the passengers change at, not at the ends of the routes
+		if i > 0 && leg.Segment.departure(leg.Schedule).Before(legs[i-1].Segment.arrival(legs[i-1].Schedule)) {
+			return model.OrderResponse{}, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("leg %d departs before leg %d arrives", i+1, i))
+		}
+		legs = append(legs, leg)
+	}
+
+	// lock all seats, every lock is released by the deferred cleanup if a later step fails
+	lockttl := model.ReservationTTL
+	var total int64
+	for _, leg := range legs {
+		for i, item := range leg.Items {
+			key := seatKey{ScheduleID: leg.Schedule.ID, WagonID: item.WagonID, SeatID: item.SeatID}
+			if err := uc.Repo.LockSeat(ctx, key.ScheduleID, key.WagonID, key.SeatID, req.SessionID, lockttl); err != nil {
+				return model.OrderResponse{}, utils.WrapError(fiber.StatusConflict, uc.Log, utils.Warn, err, fmt.Sprintf("failed to lock seat %d", item.SeatID))
+			}
+			locked = append(locked, key)
+			total += leg.Prices[i]
+		}
+	}
+
+	now := time.Now()
+	bookingTime := pgtype.Timestamp{Time: now, Valid: true}
+	expiresAt := pgtype.Timestamp{Time: now.Add(model.ReservationTTL), Valid: true}
+
+	order, err := uc.createOrder(ctx, tx, total, expiresAt)
+	if err != nil {
+		return model.OrderResponse{}, err
+	}
+
+	reservations := make([]model.Reservation, 0, len(locked))
+	for _, leg := range legs {
+		for i, item := range leg.Items {
+			itemPrice := leg.Prices[i]
+			reserve, err := tx.CreateReservation(ctx, repository.CreateReservationParams{
+				PassengerID:       item.PassengerID,
+				ScheduleID:        leg.Schedule.ID,
+				WagonID:           item.WagonID,
+				SeatID:            item.SeatID,
+				BookingDate:       bookingTime,
+				ReservationStatus: repository.StatusReservationPending,
+				DiscountID:        leg.DiscountID,
+				Price:             &itemPrice,
+				ExpiresAt:         expiresAt,
+				OrderID:           order.ID,
+				FromStop:          leg.Segment.From,
+				ToStop:            leg.Segment.To,
+				PassengerType:     leg.PassengerTypes[i],
+			})
+			if err != nil {
+				var pgErr *pgconn.PgError
+				if errors.As(err, &pgErr) && (pgErr.Code == "23505" || pgErr.Code == "23P01") {
+					return model.OrderResponse{}, utils.WrapError(fiber.StatusConflict, uc.Log, utils.Warn, err, fmt.Sprintf("seat %d already booked", item.SeatID))
+				}
+				return model.OrderResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, fmt.Sprintf("failed to create reservation for passengerID %v, seatID %v", item.PassengerID, item.SeatID))
+			}
+
+			if err := uc.recordReservationCreated(ctx, tx, reserve, reasonCreated); err != nil {
+				return model.OrderResponse{}, err
+			}
+
+			if reserve.DiscountID.Valid {
+				if err := tx.ApplyDiscountToReservation(ctx, repository.ApplyDiscountToReservationParams{
+					ReservationID: reserve.ID,
+					DiscountID:    req.DiscountID,
+				}); err != nil {
+					return model.OrderResponse{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "failed to apply discount")
+				}
+			}
+
+			reservation := toReservationModel(reserve)
+			reservation.BookingCode = order.BookingCode
+			reservations = append(reservations, reservation)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.OrderResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to commit transaction")
+	}
+	for _, key := range locked {
+		uc.releaseBookingLock(ctx, key.ScheduleID, key.WagonID, key.SeatID, req.SessionID)
+	}
+
+	uc.Log.Info("order created", zap.String("order_id", order.ID.String()), zap.String("booking_code", order.BookingCode), zap.Int("legs", len(legs)), zap.Int("seats", len(reservations)))
+	return model.OrderResponse{
+		ID:           order.ID,
+		BookingCode:  order.BookingCode,
+		TotalPrice:   order.TotalPrice,
+		ExpiresAt:    order.ExpiresAt,
+		Reservations: reservations,
+		CreatedAt:    order.CreatedAt,
+	}, nil
+}
+
+// itineraryLeg checks that every seat of the leg can be booked for its passenger and prices the leg.
+func (uc *OrderUsecase) itineraryLeg(ctx context.Context, tx repository.Transaction, discountID uuid.UUID, req model.ItineraryLegRequest) (itineraryLeg, error) {
+	schedule, err := tx.GetSchedule(ctx, req.ScheduleID)
+	if err != nil {
+		return itineraryLeg{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "failed fetch schedule")
+	}
+
+	segment, err := uc.resolveSegment(ctx, tx, schedule, req.FromStation, req.ToStation)
+	if err != nil {
+		return itineraryLeg{}, err
+	}
+
+	discountPercent, discount, err := uc.discount(ctx, tx, discountID)
+	if err != nil {
+		return itineraryLeg{}, err
+	}
+
+	prices := make([]int64, 0, len(req.Items))
+	passengerTypes := make([]string, 0, len(req.Items))
+	seats := make(map[int64]bool, len(req.Items))
+	passengers := make(map[uuid.UUID]bool, len(req.Items))
+	for _, item := range req.Items {
+		if seats[item.SeatID] {
+			return itineraryLeg{}, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("seat %d is requested more than once", item.SeatID))
+		}
+		seats[item.SeatID] = true
+
+		if passengers[item.PassengerID] {
+			return itineraryLeg{}, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("passenger %s is requested more than once", item.PassengerID))
+		}
+		passengers[item.PassengerID] = true
+
+		passenger, err := tx.GetPassenger(ctx, item.PassengerID)
+		if err != nil {
+			return itineraryLeg{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, fmt.Sprintf("no passenger found for passenger ID %s", item.PassengerID))
+		}
+		if err := uc.authorizePassenger(ctx, passenger); err != nil {
+			return itineraryLeg{}, err
+		}
+
+		wagon, err := tx.GetWagon(ctx, item.WagonID)
+		if err != nil {
+			return itineraryLeg{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "failed to fetch wagon")
+		}
+		if wagon.TrainID != schedule.TrainID {
+			return itineraryLeg{}, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("wagon %d does not belong to the scheduled train", wagon.ID))
+		}
+
+		seat, err := tx.GetSeat(ctx, item.SeatID)
+		if err != nil {
+			return itineraryLeg{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "failed to fetch seat")
+		}
+		if seat.WagonID == nil || *seat.WagonID != wagon.ID {
+			return itineraryLeg{}, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("seat %d does not belong to wagon %d", seat.ID, wagon.ID))
+		}
+
+		booked, err := tx.CheckSeatAvailability(ctx, repository.CheckSeatAvailabilityParams{
+			ScheduleID: schedule.ID,
+			WagonID:    wagon.ID,
+			SeatID:     seat.ID,
+			FromStop:   segment.From,
+			ToStop:     segment.To,
+		})
+		if err != nil {
+			return itineraryLeg{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to check seat availability")
+		}
+		if booked > 0 {
+			return itineraryLeg{}, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("seat %d already booked", seat.ID))
+		}
+
+		prices = append(prices, uc.fare(schedule, wagon.ClassType, segment, passenger.PassengerType, discountPercent).Total)
+		passengerTypes = append(passengerTypes, passenger.PassengerType)
+	}
+
+	return itineraryLeg{Schedule: schedule, Segment: segment, DiscountID: discount, Items: req.Items, Prices: prices, PassengerTypes: passengerTypes}, nil
+}
+
+// CreateAutoOrder books one seat per passenger of the requested class without the client
+// choosing seats. The group is kept together in one wagon where possible, see pickAdjacentSeats.
+func (uc *OrderUsecase) CreateAutoOrder(ctx context.Context, req model.AutoOrderRequest) (model.OrderResponse, error) {
+	if err := uc.Validate.Struct(req); err != nil {
+		return model.OrderResponse{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "validation failed")
+	}
+
+	schedule, err := uc.Repo.GetSchedule(ctx, req.ScheduleID)
+	if err != nil {
+		return model.OrderResponse{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "failed fetch schedule")
+	}
+
+	segment, err := uc.resolveSegment(ctx, uc.Repo, schedule, req.FromStation, req.ToStation)
+	if err != nil {
+		return model.OrderResponse{}, err
+	}
+
+	rows, err := uc.Repo.ListScheduleSeats(ctx, repository.ListScheduleSeatsParams{
+		FromStop:   segment.From,
+		ToStop:     segment.To,
+		ScheduleID: schedule.ID,
+	})
+	if err != nil {
+		return model.OrderResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to list schedule seats")
+	}
+
+	refs := make([]repository.SeatRef, 0, len(rows))
+	for _, row := range rows {
+		refs = append(refs, repository.SeatRef{WagonID: row.WagonID, SeatID: row.SeatID})
+	}
+	locked, err := uc.Repo.LockedSeats(ctx, req.ScheduleID, refs)
+	if err != nil {
+		return model.OrderResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to look up seat locks")
+	}
+
+	// rows come ordered by wagon, seat_number and seat_row, so the index within a wagon is the seat position
+	var wagons [][]seatCandidate
+	var lastWagon int64
+	pos := 0
+	for _, row := range rows {
+		if string(row.ClassType) != req.ClassType {
+			continue
+		}
+		if len(wagons) == 0 || row.WagonID != lastWagon {
+			wagons = append(wagons, nil)
+			lastWagon = row.WagonID
+			pos = 0
+		}
+		pos++
+		if seatState(row, locked[repository.SeatRef{WagonID: row.WagonID, SeatID: row.SeatID}]) != model.SeatStateFree {
+			continue
+		}
+		wagons[len(wagons)-1] = append(wagons[len(wagons)-1], seatCandidate{WagonID: row.WagonID, SeatID: row.SeatID, Pos: pos})
+	}
+
+	picked := pickAdjacentSeats(wagons, len(req.PassengerIDs))
+	if picked == nil {
+		return model.OrderResponse{}, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("not enough free %s seats for %d passengers", req.ClassType, len(req.PassengerIDs)))
+	}
+
+	items := make([]model.OrderItemRequest, len(picked))
+	for i, seat := range picked {
+		items[i] = model.OrderItemRequest{PassengerID: req.PassengerIDs[i], WagonID: seat.WagonID, SeatID: seat.SeatID}
+	}
+
+	return uc.CreateOrder(ctx, model.OrderRequest{
+		ScheduleID:  req.ScheduleID,
+		DiscountID:  req.DiscountID,
+		FromStation: req.FromStation,
+		ToStation:   req.ToStation,
+		Items:       items,
+		SessionID:   req.SessionID,
+	})
+}
+
+// GetOrderByCode looks an order up by its booking code, together with every reservation it holds.
+func (uc *OrderUsecase) GetOrderByCode(ctx context.Context, code string) (response model.OrderDetailResponse, err error) {
+	tx, err := uc.Repo.BeginTransaction(ctx)
+	if err != nil {
+		return model.OrderDetailResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to begin transaction")
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	order, err := tx.GetOrderByCode(ctx, strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return model.OrderDetailResponse{}, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed to get order")
+	}
+
+	owned, err := tx.ListOrderReservations(ctx, order.ID)
+	if err != nil {
+		return model.OrderDetailResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to get order reservations")
+	}
+	if err = uc.authorizeOrder(ctx, tx, order, owned); err != nil {
+		return model.OrderDetailResponse{}, err
+	}
+
+	rows, err := tx.ListFullReservationsByOrder(ctx, order.ID)
+	if err != nil {
+		return model.OrderDetailResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to get order reservations")
+	}
+
+	reservations := make([]model.ListReservationsResponse, 0, len(rows))
+	for _, row := range rows {
+		reservations = append(reservations, toListReservationsResponse(repository.GetFullReservationRow(row)))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.OrderDetailResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to commit transaction")
+	}
+
+	return model.OrderDetailResponse{
+		ID:           order.ID,
+		BookingCode:  order.BookingCode,
+		TotalPrice:   order.TotalPrice,
+		ExpiresAt:    order.ExpiresAt,
+		Reservations: reservations,
+		CreatedAt:    order.CreatedAt,
+	}, nil
+}
+
+// createOrder inserts an order under a freshly generated booking code.
+// Codes are random, so a code already in use, even by an order inserted concurrently,
+// inserts nothing and is simply drawn again without aborting the transaction.
+func (uc *UseCase) createOrder(ctx context.Context, tx repository.Transaction, totalPrice int64, expiresAt pgtype.Timestamp) (repository.Order, error) {
+	const maxAttempts = 5
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		code, err := utils.GenerateBookingCode()
+		if err != nil {
+			return repository.Order{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to generate booking code")
+		}
+
+		order, err := tx.CreateOrder(ctx, repository.CreateOrderParams{
+			BookingCode: code,
+			TotalPrice:  totalPrice,
+			ExpiresAt:   expiresAt,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			uc.Log.Debug("booking code collision", zap.String("booking_code", code))
+			continue
+		}
+		if err != nil {
+			return repository.Order{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to create order")
+		}
+		return order, nil
+	}
+
+	return repository.Order{}, fiber.NewError(fiber.StatusServiceUnavailable, "failed to allocate a unique booking code")
+}
+
+// seatCandidate is a free seat together with its position in the wagon's seat_number/seat_row order.
+type seatCandidate struct {
+	WagonID int64
+	SeatID  int64
+	Pos     int
+}
+
+// pickAdjacentSeats chooses n seats out of the free seats of each wagon.
+// It prefers the wagon where the n seats lie closest together (a span of n-1 means
+// they are side by side), and only splits the group across wagons when no single
+// wagon has n free seats. It returns nil when there are fewer than n free seats.
+func pickAdjacentSeats(wagons [][]seatCandidate, n int) []seatCandidate {
+	var best []seatCandidate
+	bestSpan := -1
+	for _, free := range wagons {
+		for i := 0; i+n <= len(free); i++ {
+			span := free[i+n-1].Pos - free[i].Pos
+			if bestSpan < 0 || span < bestSpan {
+				best, bestSpan = free[i:i+n], span
+			}
+		}
+	}
+	if best != nil {
+		return best
+	}
+
+	// no wagon fits the whole group, fill from the emptiest wagons first
+	order := make([][]seatCandidate, len(wagons))
+	copy(order, wagons)
+	sort.SliceStable(order, func(i, j int) bool { return len(order[i]) > len(order[j]) })
+
+	picked := make([]seatCandidate, 0, n)
+	for _, free := range order {
+		for _, seat := range free {
+			if len(picked) == n {
+				return picked
+			}
+			picked = append(picked, seat)
+		}
+	}
+	if len(picked) < n {
+		return nil
+	}
+	return picked
+}
+
+func toReservationModel(reserve repository.Reservation) model.Reservation {
+	return model.Reservation{
+		ID:                reserve.ID,
+		OrderID:           reserve.OrderID,
+		PassengerID:       reserve.PassengerID,
+		ScheduleID:        reserve.ScheduleID,
+		WagonID:           reserve.WagonID,
+		SeatID:            reserve.SeatID,
+		FromStop:          reserve.FromStop,
+		ToStop:            reserve.ToStop,
+		BookingDate:       reserve.BookingDate,
+		DiscountID:        reserve.DiscountID,
+		Price:             reserve.Price,
+		ReservationStatus: string(reserve.ReservationStatus),
+		ExpiresAt:         reserve.ExpiresAt,
+		CreatedAt:         reserve.CreatedAt,
+		UpdatedAt:         reserve.UpdatedAt,
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"railway-go/internal/constant/model"
 	"railway-go/internal/repository"
 	"railway-go/internal/utils"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -40,9 +41,9 @@ func (uc *PassengerUsecase) GetPassenger(ctx context.Context, id uuid.UUID) (mod
 		}
 	}()
 
-	passenger, err := tx.GetPassenger(ctx, id)
+	passenger, err := uc.authorizePassengerID(ctx, tx, id)
 	if err != nil {
-		return model.Passenger{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to get passenger")
+		return model.Passenger{}, err
 	}
 
 	if err := tx.Commit(ctx); err != nil {
@@ -50,10 +51,11 @@ func (uc *PassengerUsecase) GetPassenger(ctx context.Context, id uuid.UUID) (mod
 	}
 
 	response := model.Passenger{
-		ID:       passenger.ID,
-		Name:     passenger.Name,
-		IDNumber: passenger.IDNumber,
-		UserID:   passenger.UserID,
+		ID:            passenger.ID,
+		Name:          passenger.Name,
+		IDNumber:      passenger.IDNumber,
+		UserID:        passenger.UserID,
+		PassengerType: passenger.PassengerType,
 	}
 	return response, nil
 }
@@ -77,6 +79,24 @@ func (uc *PassengerUsecase) CreatePassenger(ctx context.Context, request model.P
 		return model.Passenger{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "validation failed")
 	}
 
+	// users can only register passengers for their own account, guests for their session,
+	// and only as the default passenger type
+	var sessionID *string
+	if session, ok := utils.SessionFrom(ctx); ok {
+		sessionID = &session.ID
+		if !session.Privileged() {
+			request.UserID = uuid.Nil
+			if session.UserID != nil {
+				request.UserID = *session.UserID
+			}
+			request.PassengerType = ""
+		}
+	}
+	passengerType, err := uc.passengerType(request.PassengerType, model.DefaultPassengerType)
+	if err != nil {
+		return model.Passenger{}, err
+	}
+
 	var userId pgtype.UUID
 	var user repository.User
 	if request.UserID == uuid.Nil {
@@ -92,10 +112,12 @@ func (uc *PassengerUsecase) CreatePassenger(ctx context.Context, request model.P
 	passengerId := uuid.New()
 
 	passenger, err := tx.CreatePassenger(ctx, repository.CreatePassengerParams{
-		ID:       passengerId,
-		Name:     request.Name,
-		IDNumber: request.IDNumber,
-		UserID:   userId,
+		ID:            passengerId,
+		Name:          request.Name,
+		IDNumber:      request.IDNumber,
+		UserID:        userId,
+		SessionID:     sessionID,
+		PassengerType: passengerType,
 	})
 
 	if err != nil {
@@ -108,11 +130,12 @@ func (uc *PassengerUsecase) CreatePassenger(ctx context.Context, request model.P
 	}
 
 	response := model.Passenger{
-		ID:        passenger.ID,
-		Name:      passenger.Name,
-		IDNumber:  passenger.IDNumber,
-		UserID:    userId,
-		CreatedAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+		ID:            passenger.ID,
+		Name:          passenger.Name,
+		IDNumber:      passenger.IDNumber,
+		UserID:        userId,
+		PassengerType: passenger.PassengerType,
+		CreatedAt:     pgtype.Timestamp{Time: time.Now(), Valid: true},
 	}
 	return response, nil
 
@@ -129,11 +152,29 @@ func (uc *PassengerUsecase) UpdatePassenger(ctx context.Context, request model.P
 		}
 	}()
 
+	passenger, err := uc.authorizePassengerID(ctx, tx, request.ID)
+	if err != nil {
+		return err
+	}
+
+	// only admins and general affairs may move a passenger to another account or change its type
+	userID := passenger.UserID
+	passengerType := passenger.PassengerType
+	if session, ok := utils.SessionFrom(ctx); ok && session.Privileged() {
+		if request.UserID != uuid.Nil {
+			userID = utils.ToPgUUID(request.UserID)
+		}
+		if passengerType, err = uc.passengerType(request.PassengerType, passenger.PassengerType); err != nil {
+			return err
+		}
+	}
+
 	r := repository.UpdatePassengerParams{
-		ID:       request.ID,
-		Name:     request.Name,
-		IDNumber: request.IDNumber,
-		UserID:   utils.ToPgUUID(request.UserID),
+		ID:            request.ID,
+		Name:          request.Name,
+		IDNumber:      request.IDNumber,
+		UserID:        userID,
+		PassengerType: passengerType,
 	}
 	err = tx.UpdatePassenger(ctx, r)
 	if err != nil {
@@ -159,6 +200,10 @@ func (uc *PassengerUsecase) DeletePassenger(ctx context.Context, id uuid.UUID) e
 		}
 	}()
 
+	if _, err = uc.authorizePassengerID(ctx, tx, id); err != nil {
+		return err
+	}
+
 	err = tx.DeletePassenger(ctx, id)
 	if err != nil {
 		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to delete passenger")
@@ -172,6 +217,20 @@ func (uc *PassengerUsecase) DeletePassenger(ctx context.Context, id uuid.UUID) e
 	return nil
 }
 
+// passengerType checks a requested passenger type against the priced ones, an empty one
+// keeps the fallback.
+func (uc *PassengerUsecase) passengerType(requested, fallback string) (string, error) {
+	requested = strings.ToLower(strings.TrimSpace(requested))
+	if requested == "" {
+		return fallback, nil
+	}
+	if _, ok := uc.Pricing.PassengerTypes[requested]; !ok {
+		return "", fiber.NewError(fiber.StatusBadRequest, "unknown passenger type")
+	}
+	return requested, nil
+}
+
+// GetPassengerByUserID returns the default passenger of the user, the oldest of its passengers.
 func (uc *PassengerUsecase) GetPassengerByUserID(ctx context.Context, userID uuid.UUID) (model.Passenger, error) {
 	tx, err := uc.Repo.BeginTransaction(ctx)
 	if err != nil {
@@ -194,10 +253,11 @@ func (uc *PassengerUsecase) GetPassengerByUserID(ctx context.Context, userID uui
 	}
 
 	response := model.Passenger{
-		ID:       passenger.ID,
-		Name:     passenger.Name,
-		IDNumber: passenger.IDNumber,
-		UserID:   passenger.UserID,
+		ID:            passenger.ID,
+		Name:          passenger.Name,
+		IDNumber:      passenger.IDNumber,
+		UserID:        passenger.UserID,
+		PassengerType: passenger.PassengerType,
 	}
 
 	return response, nil
@@ -2,10 +2,12 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"math/rand"
 	"railway-go/internal/constant/model"
 	"railway-go/internal/repository"
 	"railway-go/internal/utils"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -16,6 +18,7 @@ import (
 
 type PaymentUC interface {
 	ProcessMockPayment(ctx context.Context, req model.PaymentRequest) (model.PaymentResponse, error)
+	ProcessMockOrderPayment(ctx context.Context, code string, req model.OrderPaymentRequest) (model.PaymentResponse, error)
 	AutoCancelExpiredPayments(ctx context.Context) error
 }
 
@@ -48,7 +51,11 @@ func (uc *PaymentUsecase) ProcessMockPayment(ctx context.Context, req model.Paym
 		}
 	}()
 
-	if reservation.ReservationStatus != "pending" {
+	if err = uc.authorizeReservation(ctx, tx, reservation); err != nil {
+		return model.PaymentResponse{}, err
+	}
+
+	if reservation.ReservationStatus != repository.StatusReservationPending {
 		return model.PaymentResponse{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "reservation already paid or canceled")
 	}
 
@@ -64,9 +71,9 @@ func (uc *PaymentUsecase) ProcessMockPayment(ctx context.Context, req model.Paym
 	if success {
 		status = "success"
 		message = "Payment successful!"
-		err = tx.ConfirmReservation(ctx, req.ReservationID)
+		err = uc.transitionReservation(ctx, tx, reservation, repository.StatusReservationSuccess, reasonPaid)
 		if err != nil {
-			return model.PaymentResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to confirm reservation")
+			return model.PaymentResponse{}, err
 		}
 	} else {
 		status = "failed"
@@ -75,7 +82,8 @@ func (uc *PaymentUsecase) ProcessMockPayment(ctx context.Context, req model.Paym
 
 	uc.Log.Info("payment status", zap.Any("status", status))
 	if err = tx.CreatePayment(ctx, repository.CreatePaymentParams{
-		ReservationID:   req.ReservationID,
+		ReservationID:   utils.ToPgUUID(req.ReservationID),
+		OrderID:         reservation.OrderID,
 		PaymentMethod:   req.PaymentMethod,
 		PaymentStatus:   status,
 		Amount:          req.Amount,
@@ -86,12 +94,102 @@ func (uc *PaymentUsecase) ProcessMockPayment(ctx context.Context, req model.Paym
 		return model.PaymentResponse{Message: "failed"}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to create payment")
 	}
 
-	if err := uc.Repo.UnlockSeat(ctx, reservation.ScheduleID, reservation.WagonID, reservation.SeatID); err != nil {
-		return model.PaymentResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to unlock reservation")
+	if err := tx.Commit(ctx); err != nil {
+		return model.PaymentResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to commit transaction")
+	}
+
+	return model.PaymentResponse{
+		Transaction: transactionID,
+		Status:      status,
+		Message:     message,
+	}, nil
+}
+
+// ProcessMockOrderPayment settles every pending reservation of the order identified by code
+// with a single payment. The amount must match the combined price of those reservations.
+func (uc *PaymentUsecase) ProcessMockOrderPayment(ctx context.Context, code string, req model.OrderPaymentRequest) (response model.PaymentResponse, err error) {
+	if err := uc.Validate.Struct(req); err != nil {
+		return model.PaymentResponse{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "validation failed")
+	}
+
+	tx, err := uc.Repo.BeginTransaction(ctx)
+	if err != nil {
+		return model.PaymentResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to begin transaction")
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	order, err := tx.GetOrderByCode(ctx, strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return model.PaymentResponse{}, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed to get order")
+	}
+
+	reservations, err := tx.ListOrderReservations(ctx, order.ID)
+	if err != nil {
+		return model.PaymentResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to get order reservations")
+	}
+	if err = uc.authorizeOrder(ctx, tx, order, reservations); err != nil {
+		return model.PaymentResponse{}, err
+	}
+
+	var pending []repository.Reservation
+	var total int64
+	for _, reservation := range reservations {
+		if reservation.ReservationStatus != repository.StatusReservationPending {
+			continue
+		}
+		pending = append(pending, reservation)
+		if reservation.Price != nil {
+			total += *reservation.Price
+		}
+	}
+	if len(pending) == 0 {
+		return model.PaymentResponse{}, fiber.NewError(fiber.StatusBadRequest, "order already paid or canceled")
+	}
+	// the reservations are cancelled by the cleanup job shortly, until then they cannot be paid
+	if order.ExpiresAt.Time.Before(time.Now()) {
+		return model.PaymentResponse{}, fiber.NewError(fiber.StatusBadRequest, "order has expired")
+	}
+	if req.Amount != total {
+		return model.PaymentResponse{}, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("amount must be %d for the pending reservations of this order", total))
+	}
+
+	// simulating processing delay
+	time.Sleep(1500 * time.Millisecond)
+
+	success := rand.Intn(100) < 80
+
+	var status string
+	var message string
+	transactionID := uuid.New()
+
+	if success {
+		status = "success"
+		message = "Payment successful!"
+		for _, reservation := range pending {
+			if err = uc.transitionReservation(ctx, tx, reservation, repository.StatusReservationSuccess, reasonPaid); err != nil {
+				return model.PaymentResponse{}, err
+			}
+		}
+	} else {
+		status = "failed"
+		message = "Payment failed!"
 	}
 
-	if err := tx.DecreaseWagonSeat(ctx, reservation.WagonID); err != nil {
-		return model.PaymentResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to decrease wagon seat")
+	uc.Log.Info("order payment status", zap.String("booking_code", order.BookingCode), zap.Any("status", status))
+	if err = tx.CreatePayment(ctx, repository.CreatePaymentParams{
+		OrderID:         order.ID,
+		PaymentMethod:   req.PaymentMethod,
+		PaymentStatus:   status,
+		Amount:          req.Amount,
+		GatewayResponse: &status,
+		PaymentDate:     pgtype.Timestamp{Time: time.Now(), Valid: true},
+		TransactionID:   transactionID.String(),
+	}); err != nil {
+		return model.PaymentResponse{Message: "failed"}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to create payment")
 	}
 
 	if err := tx.Commit(ctx); err != nil {
@@ -105,28 +203,53 @@ func (uc *PaymentUsecase) ProcessMockPayment(ctx context.Context, req model.Paym
 	}, nil
 }
 
-func (uc *PaymentUsecase) AutoCancelExpiredPayments(ctx context.Context) error {
+func (uc *PaymentUsecase) AutoCancelExpiredPayments(ctx context.Context) (err error) {
 	tx, err := uc.Repo.BeginTransaction(ctx)
 	if err != nil {
 		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to begin transaction")
 	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
 	expiredReservation, err := tx.GetExpiredPayments(ctx)
 	if err != nil {
 		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to get expired payments")
 	}
 
+	scheduleIDs := make(map[int64]bool)
 	for _, res := range expiredReservation {
-		err := uc.Repo.CancelReservation(ctx, res)
+		// order payments carry no reservation, their reservations expire with the order
+		if !res.Valid {
+			continue
+		}
+		id, _ := utils.ToUUID(res)
+		reservation, err := tx.GetReservation(ctx, id)
 		if err != nil {
-			return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to cancel reservation")
+			return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to get reservation")
+		}
+		// paid or already cancelled meanwhile, nothing left to expire
+		if reservation.ReservationStatus != repository.StatusReservationPending {
+			continue
 		}
 
-		uc.Log.Info("Auto-canceling expired reservation", zap.String("reservation_id", res.String()))
+		if err := uc.transitionReservation(ctx, tx, reservation, repository.StatusReservationCancelled, reasonExpired); err != nil {
+			return err
+		}
+		scheduleIDs[reservation.ScheduleID] = true
+
+		uc.Log.Info("Auto-canceling expired reservation", zap.String("reservation_id", id.String()))
 	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to commit transaction")
 	}
+
+	for scheduleID := range scheduleIDs {
+		uc.promoteWaitlist(ctx, scheduleID)
+	}
 	uc.Log.Info("Auto-cancel expired payments completed successfully")
 	return nil
 }
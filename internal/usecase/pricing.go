@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"railway-go/internal/constant/model"
+	"railway-go/internal/repository"
+	"railway-go/internal/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// NewPricingConfig reads the fare rules from the pricing section. Invalid settings are
+// dropped, percentages never take more than the whole fare away.
+func NewPricingConfig(config *viper.Viper, log *zap.Logger) model.PricingConfig {
+	var pricing model.PricingConfig
+	if err := config.UnmarshalKey("pricing", &pricing); err != nil {
+		log.Warn("invalid pricing settings, tickets are priced at the base fare", zap.Error(err))
+		pricing = model.PricingConfig{}
+	}
+
+	if pricing.ClassSupplementPercent == nil {
+		pricing.ClassSupplementPercent = map[string]int{}
+	}
+	if pricing.PassengerTypes == nil {
+		pricing.PassengerTypes = map[string]int{}
+	}
+	if _, ok := pricing.PassengerTypes[model.DefaultPassengerType]; !ok {
+		pricing.PassengerTypes[model.DefaultPassengerType] = 0
+	}
+	for class, percent := range pricing.ClassSupplementPercent {
+		pricing.ClassSupplementPercent[class] = max(percent, -100)
+	}
+	for passengerType, percent := range pricing.PassengerTypes {
+		pricing.PassengerTypes[passengerType] = max(percent, -100)
+	}
+	pricing.BookingFee = max(pricing.BookingFee, 0)
+	pricing.TaxPercent = max(pricing.TaxPercent, 0)
+
+	return pricing
+}
+
+// fare prices one ticket of the class for the travelled legs of the schedule: the prorated
+// base fare, the class supplement, the passenger type adjustment, the discount, the booking
+// fee and the tax on all of it. Amounts are whole rupiah, percentages round towards zero.
+func (uc *UseCase) fare(schedule repository.Schedule, class repository.TipeClass, segment journeySegment, passengerType string, discountPercent int32) model.Fare {
+	base := segment.fare(schedule.Price)
+	supplement := base * int64(uc.Pricing.ClassSupplementPercent[string(class)]) / 100
+	adjustment := (base + supplement) * int64(uc.Pricing.PassengerTypes[passengerType]) / 100
+	subtotal := base + supplement + adjustment
+	discount := -(subtotal * int64(discountPercent) / 100)
+	fee := uc.Pricing.BookingFee
+	tax := (subtotal + discount + fee) * int64(uc.Pricing.TaxPercent) / 100
+
+	return model.Fare{
+		Items: []model.FareItem{
+			{Code: "base_fare", Description: "Base fare for the travelled legs", Amount: base},
+			{Code: "class_supplement", Description: fmt.Sprintf("Supplement for %s class", class), Amount: supplement},
+			{Code: "passenger_adjustment", Description: fmt.Sprintf("Adjustment for %s passengers", passengerType), Amount: adjustment},
+			{Code: "discount", Description: fmt.Sprintf("Discount of %d%%", discountPercent), Amount: discount},
+			{Code: "booking_fee", Description: "Booking fee", Amount: fee},
+			{Code: "tax", Description: fmt.Sprintf("Tax of %d%%", uc.Pricing.TaxPercent), Amount: tax},
+		},
+		Total: subtotal + discount + fee + tax,
+	}
+}
+
+// cheapestFare is the total an adult pays for the travelled legs in the cheapest class of the fare rules, without a discount.
+func (uc *UseCase) cheapestFare(schedule repository.Schedule, segment journeySegment) int64 {
+	var cheapest int64
+	for i, class := range []repository.TipeClass{repository.TipeClassEconomy, repository.TipeClassPremium, repository.TipeClassLuxury} {
+		if total := uc.fare(schedule, class, segment, model.DefaultPassengerType, 0).Total; i == 0 || total < cheapest {
+			cheapest = total
+		}
+	}
+	return cheapest
+}
+
+// discount loads the discount identified by discountID for a new booking. A nil discountID
+// is no discount and returns an invalid (NULL) discount id.
+func (uc *UseCase) discount(ctx context.Context, tx repository.Querier, discountID uuid.UUID) (int32, pgtype.UUID, error) {
+	if discountID == uuid.Nil {
+		return 0, pgtype.UUID{Valid: false}, nil
+	}
+
+	discount, err := tx.GetDiscountByID(ctx, discountID)
+	if err != nil {
+		return 0, pgtype.UUID{}, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed to get discount")
+	}
+
+	if discount.ExpiresAt.Valid && discount.ExpiresAt.Time.Before(time.Now()) {
+		return 0, pgtype.UUID{}, fiber.NewError(fiber.StatusRequestTimeout, "discount expired")
+	}
+
+	return max(discount.DiscountPercent, 0), utils.ToPgUUID(discount.ID), nil
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"railway-go/internal/constant/model"
+	"railway-go/internal/repository"
+	"railway-go/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type QuoteUC interface {
+	CreateQuote(ctx context.Context, req model.QuoteRequest) (model.Quote, error)
+}
+
+type QuoteUsecase struct {
+	*UseCase
+}
+
+func NewQuoteUsecase(useCase *UseCase) QuoteUC {
+	return &QuoteUsecase{UseCase: useCase}
+}
+
+// CreateQuote prices one ticket of a class for the requested stations and passenger type.
+// The quote is kept for model.QuoteTTL, a reservation made with it by the same session is
+// charged the quoted total even when the fare rules change in between, as long as its
+// passenger travels as the quoted type.
+func (uc *QuoteUsecase) CreateQuote(ctx context.Context, req model.QuoteRequest) (model.Quote, error) {
+	req.PassengerType = strings.ToLower(strings.TrimSpace(req.PassengerType))
+	if req.PassengerType == "" {
+		req.PassengerType = model.DefaultPassengerType
+	}
+	if err := uc.Validate.Struct(req); err != nil {
+		return model.Quote{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "validation failed")
+	}
+	if _, ok := uc.Pricing.PassengerTypes[req.PassengerType]; !ok {
+		return model.Quote{}, fiber.NewError(fiber.StatusBadRequest, "unknown passenger type")
+	}
+
+	schedule, err := uc.Repo.GetSchedule(ctx, req.ScheduleID)
+	if err != nil {
+		return model.Quote{}, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed fetch schedule")
+	}
+	if schedule.DepartureDate.Time.Before(time.Now()) {
+		return model.Quote{}, fiber.NewError(fiber.StatusBadRequest, "schedule has already departed")
+	}
+
+	segment, err := uc.resolveSegment(ctx, uc.Repo, schedule, req.FromStation, req.ToStation)
+	if err != nil {
+		return model.Quote{}, err
+	}
+
+	discountPercent, _, err := uc.discount(ctx, uc.Repo, req.DiscountID)
+	if err != nil {
+		return model.Quote{}, err
+	}
+
+	fare := uc.fare(schedule, repository.TipeClass(req.ClassType), segment, req.PassengerType, discountPercent)
+	quote := model.Quote{
+		ID:            uuid.NewString(),
+		ScheduleID:    schedule.ID,
+		ClassType:     req.ClassType,
+		FromStop:      segment.From,
+		ToStop:        segment.To,
+		PassengerType: req.PassengerType,
+		DiscountID:    req.DiscountID,
+		Items:         fare.Items,
+		Total:         fare.Total,
+		Currency:      "IDR",
+		ExpiresAt:     time.Now().Add(model.QuoteTTL),
+	}
+
+	if err := uc.Repo.SaveQuote(ctx, quote, req.SessionID, model.QuoteTTL); err != nil {
+		return model.Quote{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to save quote")
+	}
+
+	uc.Log.Info("quote created", zap.String("quote_id", quote.ID), zap.Int64("schedule_id", schedule.ID), zap.Int64("total", quote.Total))
+	return quote, nil
+}
+
+// acceptQuote takes the quote of the session for a booking of the seat, it has to be for
+// the same schedule, class, stations and discount as the booking. Quotes of the guest session
+// merged into the session are accepted too. An accepted quote can not be used again, the
+// caller restores it with restoreQuote when the booking fails, a refused one is put back here.
+func (uc *UseCase) acceptQuote(ctx context.Context, id, sessionID string, schedule repository.Schedule, wagon repository.Wagon, segment journeySegment, discountID uuid.UUID) (accepted model.Quote, err error) {
+	quote, owner, err := uc.Repo.TakeQuote(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrQuoteNotFound) {
+			return model.Quote{}, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, err.Error())
+		}
+		return model.Quote{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to get quote")
+	}
+	defer func() {
+		if err != nil {
+			uc.restoreQuote(ctx, quote, owner)
+		}
+	}()
+
+	if !ownsSessionState(ctx, owner, sessionID) {
+		return model.Quote{}, fiber.NewError(fiber.StatusForbidden, "quote belongs to another session")
+	}
+	if quote.ScheduleID != schedule.ID || quote.ClassType != string(wagon.ClassType) || quote.FromStop != segment.From || quote.ToStop != segment.To {
+		return model.Quote{}, fiber.NewError(fiber.StatusConflict, "quote does not cover the requested seat")
+	}
+	if discountID != uuid.Nil && discountID != quote.DiscountID {
+		return model.Quote{}, fiber.NewError(fiber.StatusConflict, "quote was made for another discount")
+	}
+	return quote, nil
+}
+
+// restoreQuote puts back a quote taken for a booking that did not go through, until its own expiry.
+func (uc *UseCase) restoreQuote(ctx context.Context, quote model.Quote, owner string) {
+	ttl := time.Until(quote.ExpiresAt)
+	if ttl <= 0 {
+		return
+	}
+	if err := uc.Repo.SaveQuote(ctx, quote, owner, ttl); err != nil {
+		uc.Log.Warn("failed to restore quote", zap.String("quote_id", quote.ID), zap.Error(err))
+	}
+}
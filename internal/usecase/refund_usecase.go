@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"railway-go/internal/constant/model"
+	"railway-go/internal/repository"
+	"railway-go/internal/utils"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+type RefundUC interface {
+	RefundReservation(ctx context.Context, req model.RefundRequest) (model.RefundResponse, error)
+}
+
+type RefundUsecase struct {
+	*UseCase
+	fees []model.RefundFee
+}
+
+// NewRefundUsecase reads the fee schedule from refund.fees, falling back to model.DefaultRefundFees.
+func NewRefundUsecase(useCase *UseCase, config *viper.Viper) RefundUC {
+	var fees []model.RefundFee
+	if err := config.UnmarshalKey("refund.fees", &fees); err != nil || len(fees) == 0 {
+		if err != nil {
+			useCase.Log.Warn("invalid refund fee schedule, using the default", zap.Error(err))
+		}
+		fees = model.DefaultRefundFees
+	}
+
+	// the longest notice comes first so the first matching step wins
+	sorted := append([]model.RefundFee(nil), fees...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].MinHoursBeforeDeparture > sorted[j].MinHoursBeforeDeparture
+	})
+
+	return &RefundUsecase{UseCase: useCase, fees: sorted}
+}
+
+// refundFee returns the fee percent for a refund requested the given time before departure.
+func (uc *RefundUsecase) refundFee(untilDeparture time.Duration) (int, bool) {
+	if untilDeparture <= 0 {
+		return 0, false
+	}
+	for _, fee := range uc.fees {
+		if untilDeparture >= time.Duration(fee.MinHoursBeforeDeparture)*time.Hour {
+			return fee.FeePercent, true
+		}
+	}
+	return 0, false
+}
+
+// RefundReservation cancels a paid reservation and records the refund against its payment,
+// keeping the cancellation fee that applies to how long before departure it was requested.
+// The seat is released and offered to the waitlist.
+func (uc *RefundUsecase) RefundReservation(ctx context.Context, req model.RefundRequest) (response model.RefundResponse, err error) {
+	if err := uc.Validate.Struct(req); err != nil {
+		return model.RefundResponse{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "validation failed")
+	}
+
+	tx, err := uc.Repo.BeginTransaction(ctx)
+	if err != nil {
+		return model.RefundResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to begin transaction")
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	reservation, err := tx.GetReservation(ctx, req.ReservationID)
+	if err != nil {
+		return model.RefundResponse{}, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed to get reservation")
+	}
+
+	if err = uc.authorizeReservation(ctx, tx, reservation); err != nil {
+		return model.RefundResponse{}, err
+	}
+
+	switch reservation.ReservationStatus {
+	case repository.StatusReservationSuccess:
+	case repository.StatusReservationRefunded:
+		return model.RefundResponse{}, utils.WrapError(fiber.StatusConflict, uc.Log, utils.Info, model.ErrReservationAlreadyRefunded, model.ErrReservationAlreadyRefunded.Error())
+	default:
+		return model.RefundResponse{}, utils.WrapError(fiber.StatusConflict, uc.Log, utils.Info, model.ErrReservationNotPaid, "only paid reservations can be refunded")
+	}
+
+	schedule, err := tx.GetSchedule(ctx, reservation.ScheduleID)
+	if err != nil {
+		return model.RefundResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed fetch schedule")
+	}
+
+	feePercent, ok := uc.refundFee(time.Until(schedule.DepartureDate.Time))
+	if !ok {
+		return model.RefundResponse{}, fiber.NewError(fiber.StatusConflict, "reservation can no longer be refunded")
+	}
+
+	// the reservation may have been paid on its own or as part of its order, an exchanged
+	// ticket can carry no payment of its own at all
+	paymentID := pgtype.UUID{Valid: false}
+	payment, err := tx.GetReservationPayment(ctx, reservation.ID)
+	if err == nil {
+		paymentID = utils.ToPgUUID(payment.ID)
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return model.RefundResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to get reservation payment")
+	}
+
+	var paid int64
+	if reservation.Price != nil {
+		paid = *reservation.Price
+	}
+	fee := paid * int64(feePercent) / 100
+
+	if err = uc.transitionReservation(ctx, tx, reservation, repository.StatusReservationRefunded, reasonRefunded); err != nil {
+		return model.RefundResponse{}, err
+	}
+
+	var reason *string
+	if req.Reason != "" {
+		reason = &req.Reason
+	}
+
+	refund, err := tx.CreateRefund(ctx, repository.CreateRefundParams{
+		ReservationID: reservation.ID,
+		PaymentID:     paymentID,
+		PaidAmount:    paid,
+		FeePercent:    int32(feePercent),
+		Fee:           fee,
+		Amount:        paid - fee,
+		Reason:        reason,
+	})
+	if err != nil {
+		return model.RefundResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to create refund")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return model.RefundResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to commit transaction")
+	}
+
+	uc.Log.Info("reservation refunded",
+		zap.String("reservation_id", reservation.ID.String()),
+		zap.Int("fee_percent", feePercent),
+		zap.Int64("amount", refund.Amount),
+	)
+	uc.promoteWaitlist(ctx, reservation.ScheduleID)
+
+	return model.RefundResponse{
+		ID:            refund.ID,
+		ReservationID: refund.ReservationID,
+		PaymentID:     refund.PaymentID,
+		PaidAmount:    refund.PaidAmount,
+		FeePercent:    refund.FeePercent,
+		Fee:           refund.Fee,
+		Amount:        refund.Amount,
+		Reason:        refund.Reason,
+		CreatedAt:     refund.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"railway-go/internal/constant/model"
+	"railway-go/internal/repository"
+	"railway-go/internal/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// exportBatchSize is the number of reservations read per query while exporting.
+const exportBatchSize = 500
+
+// reservationCSVHeader names the columns written by ExportReservations.
+var reservationCSVHeader = []string{
+	"reservation_id", "booking_code", "passenger_name", "passenger_id_number", "user_name", "user_email",
+	"train_name", "class_type", "seat_number", "source_station", "destination_station",
+	"departure_date", "arrival_date", "booking_date", "reservation_status", "ticket_price",
+	"discount_code", "discount_percent", "payment_amount", "payment_method", "payment_status",
+}
+
+// GetAllReservations searches every reservation with the filters, sort and page of the request.
+// Without a sort the latest bookings come first.
+func (uc *ReservationUsecase) GetAllReservations(ctx context.Context, req model.ReservationSearchRequest) ([]model.ListReservationsResponse, model.PageMetaData, error) {
+	arg, err := uc.searchReservationsParams(req)
+	if err != nil {
+		return nil, model.PageMetaData{}, err
+	}
+
+	paging := pageOf(req.Page, req.Size)
+	arg.PageLimit = int32(paging.Size)
+	arg.PageOffset = int32((paging.Page - 1) * paging.Size)
+
+	reservations, err := uc.Repo.ListReservations(ctx, arg)
+	if err != nil {
+		return nil, model.PageMetaData{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to get all reservations")
+	}
+
+	paging.TotalItem, err = uc.Repo.CountReservations(ctx, repository.CountReservationsParams{
+		DepartureFrom: arg.DepartureFrom,
+		DepartureTo:   arg.DepartureTo,
+		RouteID:       arg.RouteID,
+		TrainID:       arg.TrainID,
+		Status:        arg.Status,
+		Passenger:     arg.Passenger,
+		Email:         arg.Email,
+		DiscountCode:  arg.DiscountCode,
+	})
+	if err != nil {
+		return nil, model.PageMetaData{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to count reservations")
+	}
+	paging.TotalPage = (paging.TotalItem + int64(paging.Size) - 1) / int64(paging.Size)
+
+	response := make([]model.ListReservationsResponse, 0, len(reservations))
+	for _, reservation := range reservations {
+		response = append(response, toListReservationsResponse(repository.GetFullReservationRow(reservation)))
+	}
+	return response, paging, nil
+}
+
+// ExportReservations validates the search and returns a function writing every matching
+// reservation as CSV, in the order of the search. Paging is ignored, the reservations are read
+// in batches so the export can be streamed without loading it at once. All batches are read
+// from one snapshot, bookings made meanwhile cannot shift a row into two batches or none.
+func (uc *ReservationUsecase) ExportReservations(ctx context.Context, req model.ReservationSearchRequest) (func(w io.Writer) error, error) {
+	arg, err := uc.searchReservationsParams(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(w io.Writer) error {
+		writer := csv.NewWriter(w)
+		if err := writer.Write(reservationCSVHeader); err != nil {
+			return err
+		}
+
+		tx, err := uc.Repo.BeginSnapshot(ctx)
+		if err != nil {
+			return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to begin transaction")
+		}
+		defer func() {
+			if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				uc.Log.Warn("rollback failed", zap.Error(err))
+			}
+		}()
+
+		arg.PageLimit = exportBatchSize
+		for arg.PageOffset = 0; ; arg.PageOffset += exportBatchSize {
+			reservations, err := tx.ListReservations(ctx, arg)
+			if err != nil {
+				return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to export reservations")
+			}
+			for _, reservation := range reservations {
+				if err := writer.Write(reservationCSVRecord(toListReservationsResponse(repository.GetFullReservationRow(reservation)))); err != nil {
+					return err
+				}
+			}
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				return err
+			}
+			if len(reservations) < exportBatchSize {
+				return tx.Commit(ctx)
+			}
+		}
+	}, nil
+}
+
+// searchReservationsParams validates the search and turns it into query parameters, empty filters are left NULL.
+func (uc *ReservationUsecase) searchReservationsParams(req model.ReservationSearchRequest) (repository.ListReservationsParams, error) {
+	req.Passenger = strings.TrimSpace(req.Passenger)
+	req.Email = strings.TrimSpace(req.Email)
+	req.DiscountCode = strings.TrimSpace(req.DiscountCode)
+	if err := uc.Validate.Struct(req); err != nil {
+		return repository.ListReservationsParams{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "validation failed")
+	}
+
+	from, to, err := uc.departureRange(req.From, req.To)
+	if err != nil {
+		return repository.ListReservationsParams{}, err
+	}
+
+	arg := repository.ListReservationsParams{
+		DepartureFrom: from,
+		DepartureTo:   to,
+		Status:        optional(req.Status),
+		Passenger:     optional(req.Passenger),
+		Email:         optional(req.Email),
+		DiscountCode:  optional(req.DiscountCode),
+		SortBy:        req.SortBy,
+		SortDesc:      req.Order == "desc",
+	}
+	if req.RouteID != 0 {
+		arg.RouteID = &req.RouteID
+	}
+	if req.TrainID != 0 {
+		arg.TrainID = &req.TrainID
+	}
+	return arg, nil
+}
+
+// departureRange parses the optional YYYY-MM-DD bounds of a search, the end date is inclusive.
+func (uc *UseCase) departureRange(from, to string) (pgtype.Timestamp, pgtype.Timestamp, error) {
+	var start, end pgtype.Timestamp
+	if from != "" {
+		day, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return start, end, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "invalid from date")
+		}
+		start = pgtype.Timestamp{Time: day, Valid: true}
+	}
+	if to != "" {
+		day, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return start, end, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "invalid to date")
+		}
+		end = pgtype.Timestamp{Time: day.Add(24 * time.Hour), Valid: true}
+	}
+	return start, end, nil
+}
+
+// pageOf is the requested page, the first page of 10 items by default.
+func pageOf(page, size int) model.PageMetaData {
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 10
+	}
+	return model.PageMetaData{Page: page, Size: size}
+}
+
+// optional maps an empty filter to NULL.
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func reservationCSVRecord(reservation model.ListReservationsResponse) []string {
+	return []string{
+		reservation.ReservationID.String(),
+		reservation.BookingCode,
+		csvText(deref(reservation.PassengerName)),
+		csvText(deref(reservation.PassengerIDNumber)),
+		csvText(deref(reservation.UserName)),
+		csvText(deref(reservation.UserEmail)),
+		deref(reservation.TrainName),
+		reservation.ClassType,
+		reservation.SeatNumber,
+		deref(reservation.SourceStation),
+		deref(reservation.DestinationStation),
+		csvTime(reservation.DepartureDate),
+		csvTime(reservation.ArrivalDate),
+		csvTime(reservation.BookingDate),
+		reservation.ReservationStatus,
+		csvInt(reservation.TicketPrice),
+		deref(reservation.DiscountCode),
+		csvInt(reservation.DiscountPercent),
+		csvInt(reservation.PaymentAmount),
+		deref(reservation.PaymentMethod),
+		deref(reservation.PaymentStatus),
+	}
+}
+
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// csvText keeps text entered by users from being read as a formula by spreadsheets,
+// a cell starting with one of =+-@, a tab or a carriage return is prefixed with a quote.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func csvTime(value pgtype.Timestamp) string {
+	if !value.Valid {
+		return ""
+	}
+	return value.Time.Format(time.RFC3339)
+}
+
+func csvInt[T int32 | int64](value *T) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatInt(int64(*value), 10)
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"railway-go/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestReservationCSVRecord(t *testing.T) {
+	text := func(value string) *string { return &value }
+	wagon, seat, percent := int32(2), int32(7), int32(10)
+	price, amount := int64(150000), int64(135000)
+	at := pgtype.Timestamp{Time: time.Date(2025, 3, 1, 8, 30, 0, 0, time.UTC), Valid: true}
+
+	row := repository.GetFullReservationRow{
+		ReservationID:      uuid.New(),
+		BookingCode:        "ABC234",
+		PassengerName:      text("Budi"),
+		PassengerIDNumber:  text("3201010101010001"),
+		UserName:           text("budi"),
+		UserEmail:          text("budi@example.com"),
+		DepartureDate:      at,
+		ArrivalDate:        at,
+		TicketPrice:        &price,
+		TrainName:          text("Argo Bromo"),
+		ClassType:          repository.NullTipeClass{TipeClass: repository.TipeClassEconomy, Valid: true},
+		WagonNumber:        &wagon,
+		SeatNumber:         &seat,
+		SeatRow:            repository.NullSeatRow{SeatRow: repository.SeatRowA, Valid: true},
+		BookingDate:        at,
+		ReservationStatus:  repository.StatusReservationSuccess,
+		SourceStation:      text("Gambir"),
+		DestinationStation: text("Surabaya Pasarturi"),
+		DiscountCode:       text("MUDIK10"),
+		DiscountPercent:    &percent,
+		PaymentAmount:      &amount,
+		PaymentMethod:      text("transfer"),
+		PaymentStatus:      text("success"),
+	}
+
+	record := reservationCSVRecord(toListReservationsResponse(row))
+	if len(record) != len(reservationCSVHeader) {
+		t.Fatalf("record has %d columns, header has %d", len(record), len(reservationCSVHeader))
+	}
+	for i, column := range reservationCSVHeader {
+		if record[i] == "" {
+			t.Errorf("column %s is empty", column)
+		}
+	}
+}
+
+func TestMyReservationsCarryStatus(t *testing.T) {
+	wagon, seat := int32(1), int32(3)
+	row := repository.ListUserReservationsRow{
+		ReservationID:     uuid.New(),
+		WagonNumber:       &wagon,
+		SeatNumber:        &seat,
+		SeatRow:           repository.NullSeatRow{SeatRow: repository.SeatRowB, Valid: true},
+		ReservationStatus: repository.StatusReservationPending,
+	}
+
+	response := toListReservationsResponse(repository.GetFullReservationRow(row))
+	if response.ReservationStatus != string(repository.StatusReservationPending) {
+		t.Errorf("reservation_status = %q, want %q", response.ReservationStatus, repository.StatusReservationPending)
+	}
+}
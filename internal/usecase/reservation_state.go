@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"railway-go/internal/constant/model"
+	"railway-go/internal/repository"
+	"railway-go/internal/utils"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Reasons recorded in the status history of a reservation.
+const (
+	reasonCreated   = "reservation created"
+	reasonPromoted  = "promoted from the waitlist"
+	reasonPaid      = "payment succeeded"
+	reasonCancelled = "cancelled on request"
+	reasonExpired   = "payment deadline passed"
+	reasonExchanged = "exchanged for another reservation"
+	reasonRefunded  = "refunded"
+)
+
+type reservationTransition struct {
+	From repository.StatusReservation
+	To   repository.StatusReservation
+}
+
+// reservationTransitions is the reservation state machine. Every allowed status change is
+// listed with the reasons that may cause it, cancelled and refunded reservations are final.
+// A paid reservation can only be cancelled by exchanging it, otherwise it has to be refunded.
+var reservationTransitions = map[reservationTransition][]string{
+	{repository.StatusReservationPending, repository.StatusReservationSuccess}:   {reasonPaid},
+	{repository.StatusReservationPending, repository.StatusReservationCancelled}: {reasonCancelled, reasonExpired, reasonExchanged},
+	{repository.StatusReservationSuccess, repository.StatusReservationCancelled}: {reasonExchanged},
+	{repository.StatusReservationSuccess, repository.StatusReservationRefunded}:  {reasonRefunded},
+}
+
+// canTransitionReservation reports whether a reservation may move between the statuses for the given reason.
+func canTransitionReservation(from, to repository.StatusReservation, reason string) bool {
+	return slices.Contains(reservationTransitions[reservationTransition{From: from, To: to}], reason)
+}
+
+// transitionReservation moves the reservation to a new status and records the change.
+// The update only applies while the reservation still has the status it was loaded with,
+// so a concurrent change is reported as a conflict instead of being overwritten.
+func (uc *UseCase) transitionReservation(ctx context.Context, tx repository.Querier, reservation repository.Reservation, to repository.StatusReservation, reason string) error {
+	if !canTransitionReservation(reservation.ReservationStatus, to, reason) {
+		return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("a %s reservation cannot become %s", reservation.ReservationStatus, to))
+	}
+
+	updated, err := tx.UpdateReservationStatus(ctx, repository.UpdateReservationStatusParams{
+		ID:         reservation.ID,
+		FromStatus: reservation.ReservationStatus,
+		ToStatus:   to,
+	})
+	if err != nil {
+		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to update reservation status")
+	}
+	if updated == 0 {
+		return fiber.NewError(fiber.StatusConflict, "reservation status changed in the meantime")
+	}
+
+	return uc.recordReservationStatus(ctx, tx, reservation.ID, reservation.ReservationStatus, to, reason)
+}
+
+// recordReservationCreated records the initial status of a freshly inserted reservation.
+func (uc *UseCase) recordReservationCreated(ctx context.Context, tx repository.Querier, reservation repository.Reservation, reason string) error {
+	return uc.recordReservationStatus(ctx, tx, reservation.ID, "", reservation.ReservationStatus, reason)
+}
+
+// recordReservationStatus writes a status history entry on behalf of the actor of ctx,
+// an empty from status marks the creation of the reservation.
+func (uc *UseCase) recordReservationStatus(ctx context.Context, tx repository.Querier, id uuid.UUID, from, to repository.StatusReservation, reason string) error {
+	if err := tx.CreateReservationStatusHistory(ctx, repository.CreateReservationStatusHistoryParams{
+		ReservationID: id,
+		FromStatus:    repository.NullStatusReservation{StatusReservation: from, Valid: from != ""},
+		ToStatus:      to,
+		Reason:        reason,
+		Actor:         utils.ActorFrom(ctx),
+	}); err != nil {
+		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to record reservation status")
+	}
+	return nil
+}
+
+func toStatusHistory(entries []repository.ReservationStatusHistory) []model.ReservationStatusChange {
+	history := make([]model.ReservationStatusChange, 0, len(entries))
+	for _, entry := range entries {
+		change := model.ReservationStatusChange{
+			ToStatus:  string(entry.ToStatus),
+			Reason:    entry.Reason,
+			Actor:     entry.Actor,
+			ChangedAt: entry.CreatedAt.Time,
+		}
+		if entry.FromStatus.Valid {
+			from := string(entry.FromStatus.StatusReservation)
+			change.FromStatus = &from
+		}
+		history = append(history, change)
+	}
+	return history
+}
@@ -3,9 +3,11 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"io"
 	"railway-go/internal/constant/model"
 	"railway-go/internal/repository"
 	"railway-go/internal/utils"
+	"slices"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -21,8 +23,12 @@ type ReservationUC interface {
 	ConfirmReservation(ctx context.Context, id uuid.UUID) error
 	GetReservationById(ctx context.Context, id uuid.UUID) (repository.Reservation, error)
 	DeleteReservation(ctx context.Context, id uuid.UUID) error
-	GetAllReservations(ctx context.Context) ([]model.ListReservationsResponse, int64, error)
+	GetAllReservations(ctx context.Context, req model.ReservationSearchRequest) ([]model.ListReservationsResponse, model.PageMetaData, error)
+	ExportReservations(ctx context.Context, req model.ReservationSearchRequest) (func(w io.Writer) error, error)
+	GetMyReservations(ctx context.Context, req model.MyReservationsRequest) ([]model.ListReservationsResponse, model.PageMetaData, error)
 	AutoDeleteReservations(ctx context.Context) error
+	PurgeSeatLocks(ctx context.Context) error
+	ExchangeReservation(ctx context.Context, req model.ExchangeRequest) (model.ExchangeResponse, error)
 }
 type ReservationUsecase struct {
 	*UseCase
@@ -56,19 +62,19 @@ func NewReservationUsecase(useCase *UseCase) ReservationUC {
 
 // CreateReservation handles the process of creating a new reservation for a train seat.
 // It performs the following steps:
-//  1. Begins a database transaction.
-//  2. Retrieves the schedule, wagon, and seat based on the request.
-//  3. Validates that the seat belongs to the selected wagon.
-//  4. Validates the request body.
-//  5. Checks if the seat is already booked or locked.
-//  6. Locks the seat for a specified TTL to prevent race conditions.
-//  7. Calculates the price, applying a discount if provided.
-//  8. Retrieves or creates a passenger associated with the user.
-//  9. Creates the reservation record in the database.
-//  10. Applies the discount to the reservation if applicable.
-//  11. Commits the transaction and returns the reservation details.
+//  1. Converts the seat hold named by hold_id, which must belong to the calling session,
+//     or locks the seat for model.ReservationTTL before any database work.
+//  2. Begins a database transaction.
+//  3. Retrieves or creates a passenger associated with the user.
+//  4. Retrieves the schedule, wagon, and seat based on the request.
+//  5. Checks if the seat is already booked for an overlapping part of the route.
+//  6. Calculates the price, applying a discount if provided.
+//  7. Creates the order and the reservation record in the database.
+//  8. Applies the discount to the reservation if applicable.
+//  9. Commits the transaction, releases the seat lock and drops the converted hold.
 //
-// If any step fails, the transaction is rolled back and an appropriate error is returned.
+// If any step fails, the transaction is rolled back, a lock taken by this call is released
+// and an appropriate error is returned. A converted hold is left untouched on failure.
 //
 // Parameters:
 //   - ctx: context.Context for request-scoped values and cancellation.
@@ -77,9 +83,41 @@ func NewReservationUsecase(useCase *UseCase) ReservationUC {
 // Returns:
 //   - model.Reservation: The created reservation object.
 //   - error: An error if the reservation could not be created.
-func (uc *ReservationUsecase) CreateReservation(ctx context.Context, req model.ReservationRequest) (model.Reservation, error) {
+func (uc *ReservationUsecase) CreateReservation(ctx context.Context, req model.ReservationRequest) (response model.Reservation, err error) {
+	var hold *model.SeatHold
+	// a quote taken for the booking goes back when the booking fails
+	var accepted *model.Quote
+	if req.HoldID != "" {
+		owned, err := uc.ownedHold(ctx, req.HoldID, req.SessionID)
+		if err != nil {
+			return model.Reservation{}, err
+		}
+		if req.ScheduleID == 0 && req.WagonID == 0 && req.Seat_id == 0 {
+			req.ScheduleID, req.WagonID, req.Seat_id = owned.ScheduleID, owned.WagonID, owned.SeatID
+		}
+		if req.ScheduleID != owned.ScheduleID || req.WagonID != owned.WagonID || req.Seat_id != owned.SeatID {
+			return model.Reservation{}, fiber.NewError(fiber.StatusConflict, "seat hold does not cover the requested seat")
+		}
+		hold = &owned
+	}
+
+	// validate request
+	if err := uc.Validate.Struct(req); err != nil {
+		return model.Reservation{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Error, err, "validation failed")
+	}
+
+	// lock the seat before touching the database, a held seat is already locked by its hold
+	if hold == nil {
+		if err := uc.Repo.LockSeat(ctx, req.ScheduleID, req.WagonID, req.Seat_id, req.SessionID, model.ReservationTTL); err != nil {
+			return model.Reservation{}, utils.WrapError(fiber.StatusConflict, uc.Log, utils.Warn, err, "seat is already held")
+		}
+	}
+
 	tx, err := uc.Repo.BeginTransaction(ctx)
 	if err != nil {
+		if hold == nil {
+			_ = uc.Repo.UnlockSeat(ctx, req.ScheduleID, req.WagonID, req.Seat_id, req.SessionID)
+		}
 		return model.Reservation{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to begin transaction")
 	}
 
@@ -93,38 +131,47 @@ func (uc *ReservationUsecase) CreateReservation(ctx context.Context, req model.R
 			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
 				uc.Log.Error("rollback failed", zap.Error(rollbackErr))
 			}
+			if accepted != nil {
+				uc.restoreQuote(ctx, *accepted, req.SessionID)
+			}
+			// ensure seat lock is removed if reservation fails
+			if hold == nil {
+				if unlockErr := uc.Repo.UnlockSeat(ctx, req.ScheduleID, req.WagonID, req.Seat_id, req.SessionID); unlockErr != nil {
+					uc.Log.Warn("failed to unlock seat", zap.Int64("seat_id", req.Seat_id), zap.Error(unlockErr))
+				}
+			}
 		}
 	}()
 
-	// validate request
-	if err := uc.Validate.Struct(req); err != nil {
-		return model.Reservation{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Error, err, "validation failed")
-	}
-
 	var passenger repository.Passenger
 	passengerID, _ := utils.ToUUID(req.PassengerID)
 	// this condition allows all role except guest to auto get passenger with user_id
 	if passengerID == uuid.Nil {
 		userID := utils.ToPgUUID(req.UserId)
-		passenger, err = tx.GetPassengerByUser(ctx, userID)
+		passengers, err := tx.ListPassengersByUser(ctx, userID)
 		if err != nil {
-			err := uc.Repo.UnlockSeat(ctx, req.ScheduleID, req.WagonID, req.Seat_id)
-			if err != nil {
-				return model.Reservation{}, err
-			}
+			return model.Reservation{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to get passengers of the user")
+		}
+		switch len(passengers) {
+		case 0:
 			return model.Reservation{}, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("No passenger found for the provided user ID: %s. Please ensure the passenger exists or register a new passenger.", req.UserId))
+		case 1:
+			passenger = passengers[0]
+		default:
+			// merged guest bookings or added passengers leave no passenger to pick on the user's behalf
+			return model.Reservation{}, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("The user %s has %d passengers, please choose one with passenger_id.", req.UserId, len(passengers)))
 		}
 	} else {
 		passenger, err = tx.GetPassenger(ctx, passengerID)
 		if err != nil {
-			err := uc.Repo.UnlockSeat(ctx, req.ScheduleID, req.WagonID, req.Seat_id)
-			if err != nil {
-				return model.Reservation{}, err
-			}
 			return model.Reservation{}, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("No passenger found for the provided passenger ID: %s. Please ensure the passenger_id is correct or register a new passenger", passengerID))
 		}
 	}
 
+	if err = uc.authorizePassenger(ctx, passenger); err != nil {
+		return model.Reservation{}, err
+	}
+
 	// uc.Log.Info("got passenger", zap.Any("passenger id: ", passenger.ID))
 
 	// get the Schedule
@@ -143,10 +190,26 @@ func (uc *ReservationUsecase) CreateReservation(ctx context.Context, req model.R
 		return model.Reservation{}, fiber.NewError(fiber.StatusBadRequest, "failed to fetch seat")
 	}
 
+	segment, err := uc.resolveSegment(ctx, tx, schedule, req.FromStation, req.ToStation)
+	if err != nil {
+		return model.Reservation{}, err
+	}
+	if hold != nil {
+		// without explicit stations the reservation covers what was held
+		if req.FromStation == "" && req.ToStation == "" {
+			segment.From, segment.To = hold.FromStop, hold.ToStop
+		}
+		if segment.From != hold.FromStop || segment.To != hold.ToStop {
+			return model.Reservation{}, fiber.NewError(fiber.StatusConflict, "seat hold does not cover the requested stations")
+		}
+	}
+
 	bookedParams := repository.CheckSeatAvailabilityParams{
 		ScheduleID: schedule.ID,
 		WagonID:    wagon.ID,
 		SeatID:     seat.ID,
+		FromStop:   segment.From,
+		ToStop:     segment.To,
 	}
 	// counting from reservations where schedule, wagon , seat
 	booked, err := tx.CheckSeatAvailability(ctx, bookedParams)
@@ -159,66 +222,67 @@ func (uc *ReservationUsecase) CreateReservation(ctx context.Context, req model.R
 		return model.Reservation{}, fiber.NewError(fiber.StatusConflict, "seat already booked")
 	}
 
-	// default price
-	price := schedule.Price
-
+	// an accepted quote fixes the price the customer was shown, otherwise the ticket is priced
+	// now. Either way the ticket is priced for the type of the passenger.
+	var price int64
 	var discountID pgtype.UUID
-	if req.DiscountID == uuid.Nil {
-		discountID = pgtype.UUID{Valid: false}
-	} else {
-		discountID = utils.ToPgUUID(req.DiscountID)
-		discount, err := tx.GetDiscountByID(ctx, req.DiscountID)
+	passengerType := passenger.PassengerType
+	if req.QuoteID != "" {
+		var quote model.Quote
+		quote, err = uc.acceptQuote(ctx, req.QuoteID, req.SessionID, schedule, wagon, segment, req.DiscountID)
 		if err != nil {
-			return model.Reservation{}, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed to get discount")
+			return model.Reservation{}, err
 		}
-		if discount.DiscountPercent > 0 {
-			price -= price * int64(discount.DiscountPercent) / 100
+		accepted = &quote
+		if quote.PassengerType != passengerType {
+			return model.Reservation{}, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("quote was made for a %s, the passenger travels as %s", quote.PassengerType, passengerType))
 		}
-		expired := time.Now()
-		if discount.ExpiresAt.Valid && discount.ExpiresAt.Time.Before(expired) {
-			return model.Reservation{}, utils.WrapError(fiber.StatusRequestTimeout, uc.Log, utils.Warn, nil, "discount expired")
+		price, req.DiscountID = quote.Total, quote.DiscountID
+		if quote.DiscountID != uuid.Nil {
+			discountID = utils.ToPgUUID(quote.DiscountID)
 		}
-
+	} else {
+		var discountPercent int32
+		discountPercent, discountID, err = uc.discount(ctx, tx, req.DiscountID)
+		if err != nil {
+			return model.Reservation{}, err
+		}
+		price = uc.fare(schedule, wagon.ClassType, segment, passengerType, discountPercent).Total
 	}
 
-	// uc.Log.Info("reservation params", zap.Any("discount", discount))
-
-	// check if a discount is provided
-
 	bookingTime := pgtype.Timestamp{
 		Time:  time.Now(),
 		Valid: true,
 	}
 	expiresAt := pgtype.Timestamp{
-		Time:  time.Now().Add(15 * time.Minute),
+		Time:  time.Now().Add(model.ReservationTTL),
 		Valid: true,
 	}
 
+	// every reservation belongs to an order, a single seat booking gets an order of its own
+	order, err := uc.createOrder(ctx, tx, price, expiresAt)
+	if err != nil {
+		return model.Reservation{}, err
+	}
+
 	params := repository.CreateReservationParams{
 		PassengerID:       passenger.ID,
 		ScheduleID:        schedule.ID,
 		WagonID:           wagon.ID,
 		SeatID:            seat.ID,
 		BookingDate:       bookingTime,
-		ReservationStatus: "pending",
+		ReservationStatus: repository.StatusReservationPending,
 		ExpiresAt:         expiresAt,
 		DiscountID:        discountID,
 		Price:             &price,
-	}
-
-	lockttl := 5 * time.Minute
-	if err := uc.Repo.LockSeat(ctx, schedule.ID, wagon.ID, seat.ID, lockttl); err != nil {
-		return model.Reservation{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to lock seat")
+		OrderID:           order.ID,
+		FromStop:          segment.From,
+		ToStop:            segment.To,
+		PassengerType:     passengerType,
 	}
 
 	reserve, err := tx.CreateReservation(ctx, params)
 	if err != nil {
-		// var pgErr *pgconn.PgError
-		// if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-		// 	_ = uc.Repo.UnlockSeat(ctx, req.ScheduleID, req.WagonID, req.Seat_id)
-		// 	return model.Reservation{}, utils.WrapError(fiber.StatusConflict, uc.Log, utils.Warn, err, fmt.Sprintf("seat already booked:%v", booked))
-		// }
-		// ensure seat lock is removed if reservation fails
 		return model.Reservation{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, fmt.Sprintf("failed to create reservation for passengerID %v, scheduleID %v, wagonID %v, seatID %v",
 			passenger.ID,
 			req.ScheduleID,
@@ -228,26 +292,30 @@ func (uc *ReservationUsecase) CreateReservation(ctx context.Context, req model.R
 		)
 	}
 
+	if err = uc.recordReservationCreated(ctx, tx, reserve, reasonCreated); err != nil {
+		return model.Reservation{}, err
+	}
+
 	if reserve.DiscountID.Valid {
-		err := tx.ApplyDiscountToReservation(ctx, repository.ApplyDiscountToReservationParams{
+		err = tx.ApplyDiscountToReservation(ctx, repository.ApplyDiscountToReservationParams{
 			ReservationID: reserve.ID,
 			DiscountID:    req.DiscountID,
 		})
 		if err != nil {
-			err := uc.Repo.UnlockSeat(ctx, req.ScheduleID, req.WagonID, req.Seat_id)
-			if err != nil {
-				return model.Reservation{}, err
-			}
 			return model.Reservation{}, fiber.NewError(fiber.StatusBadRequest, "failed to apply discount")
 		}
 	}
 
-	response := model.Reservation{
+	response = model.Reservation{
 		ID:                reserve.ID,
-		PassengerID:       reserve.ID,
+		OrderID:           order.ID,
+		BookingCode:       order.BookingCode,
+		PassengerID:       reserve.PassengerID,
 		ScheduleID:        reserve.ScheduleID,
 		WagonID:           reserve.WagonID,
 		SeatID:            reserve.SeatID,
+		FromStop:          reserve.FromStop,
+		ToStop:            reserve.ToStop,
 		BookingDate:       reserve.BookingDate,
 		DiscountID:        reserve.DiscountID,
 		Price:             reserve.Price,
@@ -258,10 +326,233 @@ func (uc *ReservationUsecase) CreateReservation(ctx context.Context, req model.R
 	}
 
 	// commit transaction
-	if err := tx.Commit(ctx); err != nil {
+	if err = tx.Commit(ctx); err != nil {
 		return model.Reservation{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to commit transaction")
 	}
 
+	// a converted hold ends here too, its lock is owned by the session that made the hold
+	owner := req.SessionID
+	if hold != nil {
+		owner = hold.SessionID
+	}
+	uc.releaseBookingLock(ctx, req.ScheduleID, req.WagonID, req.Seat_id, owner)
+	if hold != nil {
+		if err := uc.Repo.DeleteSeatHold(ctx, hold.ID); err != nil {
+			uc.Log.Warn("failed to delete converted seat hold", zap.String("hold_id", hold.ID), zap.Error(err))
+		}
+	}
+
+	return response, nil
+}
+
+// ExchangeReservation moves a pending or paid reservation to another seat, schedule or part of
+// the route in one transaction. The new seat is locked before anything changes, the old
+// reservation is cancelled, a new one is created at the new fare and both are linked.
+// For a paid reservation the fare difference is collected or refunded right away and the
+// new reservation is paid too; a pending reservation stays pending at the new price.
+func (uc *ReservationUsecase) ExchangeReservation(ctx context.Context, req model.ExchangeRequest) (response model.ExchangeResponse, err error) {
+	if err := uc.Validate.Struct(req); err != nil {
+		return model.ExchangeResponse{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "validation failed")
+	}
+
+	// secure the new seat first, the old one is only given up once the new one is booked
+	if err := uc.Repo.LockSeat(ctx, req.ScheduleID, req.WagonID, req.SeatID, req.SessionID, model.ReservationTTL); err != nil {
+		return model.ExchangeResponse{}, utils.WrapError(fiber.StatusConflict, uc.Log, utils.Warn, err, "seat is already held")
+	}
+
+	tx, err := uc.Repo.BeginTransaction(ctx)
+	if err != nil {
+		_ = uc.Repo.UnlockSeat(ctx, req.ScheduleID, req.WagonID, req.SeatID, req.SessionID)
+		return model.ExchangeResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to begin transaction")
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			if unlockErr := uc.Repo.UnlockSeat(ctx, req.ScheduleID, req.WagonID, req.SeatID, req.SessionID); unlockErr != nil {
+				uc.Log.Warn("failed to unlock seat", zap.Int64("seat_id", req.SeatID), zap.Error(unlockErr))
+			}
+		}
+	}()
+
+	original, err := tx.GetReservation(ctx, req.ReservationID)
+	if err != nil {
+		return model.ExchangeResponse{}, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed to get reservation")
+	}
+
+	if err = uc.authorizeReservation(ctx, tx, original); err != nil {
+		return model.ExchangeResponse{}, err
+	}
+
+	paid := original.ReservationStatus == repository.StatusReservationSuccess
+	if !paid && original.ReservationStatus != repository.StatusReservationPending {
+		return model.ExchangeResponse{}, fiber.NewError(fiber.StatusConflict, "only pending or paid reservations can be exchanged")
+	}
+
+	originalSchedule, err := tx.GetSchedule(ctx, original.ScheduleID)
+	if err != nil {
+		return model.ExchangeResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed fetch schedule")
+	}
+	if originalSchedule.DepartureDate.Time.Before(time.Now()) {
+		return model.ExchangeResponse{}, fiber.NewError(fiber.StatusConflict, "reservation can no longer be exchanged after departure")
+	}
+
+	schedule, wagon, seat, err := uc.scheduleSeat(ctx, tx, req.ScheduleID, req.WagonID, req.SeatID)
+	if err != nil {
+		return model.ExchangeResponse{}, err
+	}
+	if schedule.DepartureDate.Time.Before(time.Now()) {
+		return model.ExchangeResponse{}, fiber.NewError(fiber.StatusBadRequest, "schedule has already departed")
+	}
+
+	segment, err := uc.resolveSegment(ctx, tx, schedule, req.FromStation, req.ToStation)
+	if err != nil {
+		return model.ExchangeResponse{}, err
+	}
+
+	if schedule.ID == original.ScheduleID && wagon.ID == original.WagonID && seat.ID == original.SeatID &&
+		segment.From == original.FromStop && segment.To == original.ToStop {
+		return model.ExchangeResponse{}, fiber.NewError(fiber.StatusBadRequest, "the reservation already holds this seat")
+	}
+
+	// cancel first, so moving within the same seat or route does not collide with itself
+	if err = uc.transitionReservation(ctx, tx, original, repository.StatusReservationCancelled, reasonExchanged); err != nil {
+		return model.ExchangeResponse{}, err
+	}
+	// the new reservation gets an order of its own, the unpaid order it leaves must not stay payable
+	if !paid {
+		if err = tx.ExpireSettledOrder(ctx, original.OrderID); err != nil {
+			return model.ExchangeResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to close superseded order")
+		}
+	}
+
+	booked, err := tx.CheckSeatAvailability(ctx, repository.CheckSeatAvailabilityParams{
+		ScheduleID: schedule.ID,
+		WagonID:    wagon.ID,
+		SeatID:     seat.ID,
+		FromStop:   segment.From,
+		ToStop:     segment.To,
+	})
+	if err != nil {
+		return model.ExchangeResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to check seat availability")
+	}
+	if booked > 0 {
+		return model.ExchangeResponse{}, fiber.NewError(fiber.StatusConflict, "seat already booked")
+	}
+
+	// the passenger type and discount of the original booking carry over to the new fare
+	var discountPercent int32
+	if original.DiscountID.Valid {
+		discountID, _ := utils.ToUUID(original.DiscountID)
+		discount, err := tx.GetDiscountByID(ctx, discountID)
+		if err != nil {
+			return model.ExchangeResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to get discount")
+		}
+		discountPercent = discount.DiscountPercent
+	}
+	price := uc.fare(schedule, wagon.ClassType, segment, original.PassengerType, discountPercent).Total
+
+	var originalPrice int64
+	if original.Price != nil {
+		originalPrice = *original.Price
+	}
+	difference := price - originalPrice
+
+	status := repository.StatusReservationPending
+	if paid {
+		status = repository.StatusReservationSuccess
+	}
+
+	now := time.Now()
+	expiresAt := pgtype.Timestamp{Time: now.Add(model.ReservationTTL), Valid: true}
+
+	order, err := uc.createOrder(ctx, tx, price, expiresAt)
+	if err != nil {
+		return model.ExchangeResponse{}, err
+	}
+
+	reserve, err := tx.CreateReservation(ctx, repository.CreateReservationParams{
+		PassengerID:       original.PassengerID,
+		ScheduleID:        schedule.ID,
+		WagonID:           wagon.ID,
+		SeatID:            seat.ID,
+		BookingDate:       pgtype.Timestamp{Time: now, Valid: true},
+		ReservationStatus: status,
+		DiscountID:        original.DiscountID,
+		Price:             &price,
+		ExpiresAt:         expiresAt,
+		OrderID:           order.ID,
+		FromStop:          segment.From,
+		ToStop:            segment.To,
+		PassengerType:     original.PassengerType,
+	})
+	if err != nil {
+		return model.ExchangeResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to create reservation")
+	}
+
+	if err = uc.recordReservationCreated(ctx, tx, reserve, reasonExchanged); err != nil {
+		return model.ExchangeResponse{}, err
+	}
+
+	response = model.ExchangeResponse{
+		OriginalReservationID: original.ID,
+		FareDifference:        difference,
+	}
+
+	switch {
+	case !paid:
+		response.AmountDue = price
+	case difference != 0:
+		// settle the difference of a paid ticket through the mock gateway
+		payment := repository.CreatePaymentParams{
+			ReservationID: utils.ToPgUUID(reserve.ID),
+			OrderID:       order.ID,
+			PaymentDate:   pgtype.Timestamp{Time: now, Valid: true},
+			TransactionID: uuid.NewString(),
+		}
+		if difference > 0 {
+			if req.PaymentMethod == "" {
+				return model.ExchangeResponse{}, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("payment_method is required to collect the fare difference of %d", difference))
+			}
+			payment.PaymentMethod = req.PaymentMethod
+			payment.Amount = difference
+			payment.PaymentStatus = "success"
+			response.AmountCollected = difference
+		} else {
+			payment.PaymentMethod = "exchange"
+			payment.Amount = -difference
+			payment.PaymentStatus = "refunded"
+			response.AmountRefunded = -difference
+		}
+		payment.GatewayResponse = &payment.PaymentStatus
+		if err = tx.CreatePayment(ctx, payment); err != nil {
+			return model.ExchangeResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to record fare difference")
+		}
+	}
+
+	if _, err = tx.CreateReservationExchange(ctx, repository.CreateReservationExchangeParams{
+		OriginalReservationID: original.ID,
+		NewReservationID:      reserve.ID,
+		FareDifference:        difference,
+	}); err != nil {
+		return model.ExchangeResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to link exchanged reservations")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return model.ExchangeResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to commit transaction")
+	}
+
+	// the old seat carries no lock, its booking released it on commit like this one
+	uc.releaseBookingLock(ctx, schedule.ID, wagon.ID, seat.ID, req.SessionID)
+
+	uc.Log.Info("reservation exchanged",
+		zap.String("original_reservation_id", original.ID.String()),
+		zap.String("reservation_id", reserve.ID.String()),
+		zap.Int64("fare_difference", difference),
+	)
+	uc.promoteWaitlist(ctx, original.ScheduleID)
+
+	response.Reservation = toReservationModel(reserve)
+	response.Reservation.BookingCode = order.BookingCode
 	return response, nil
 }
 
@@ -279,33 +570,30 @@ func (uc *ReservationUsecase) GetDetailReservation(ctx context.Context, id uuid.
 		}
 	}()
 
+	owned, err := tx.GetReservation(ctx, id)
+	if err != nil {
+		return model.ListReservationsResponse{},
+			utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed to get reservation")
+	}
+
+	if err = uc.authorizeReservation(ctx, tx, owned); err != nil {
+		return model.ListReservationsResponse{}, err
+	}
+
 	reservation, err := tx.GetFullReservation(ctx, id)
 	if err != nil {
 		return model.ListReservationsResponse{},
 			utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to get reservation")
 	}
 
-	seatNumber := fmt.Sprintf("Gerbong %d/%s-%d", *reservation.WagonNumber, reservation.SeatRow.SeatRow, *reservation.SeatNumber)
+	response := toListReservationsResponse(reservation)
 
-	response := model.ListReservationsResponse{
-		ReservationID:      reservation.ReservationID,
-		PassengerName:      reservation.PassengerName,
-		PassengerIDNumber:  reservation.PassengerIDNumber,
-		UserEmail:          reservation.UserEmail,
-		DepartureDate:      reservation.DepartureDate,
-		ArrivalDate:        reservation.ArrivalDate,
-		TicketPrice:        reservation.TicketPrice,
-		TrainName:          reservation.TrainName,
-		ClassType:          string(reservation.ClassType.TipeClass), // Extract the string value from NullTipeClass
-		SeatNumber:         seatNumber,
-		BookingDate:        reservation.BookingDate, // Extract the string value from NullTipeClass
-		SourceStation:      reservation.SourceStation,
-		DestinationStation: reservation.DestinationStation,
-		DiscountCode:       reservation.DiscountCode,
-		PaymentAmount:      reservation.PaymentAmount,
-		PaymentMethod:      reservation.PaymentMethod,
-		PaymentStatus:      reservation.PaymentStatus,
+	history, err := tx.ListReservationStatusHistory(ctx, id)
+	if err != nil {
+		return model.ListReservationsResponse{},
+			utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to get reservation status history")
 	}
+	response.StatusHistory = toStatusHistory(history)
 
 	return response, nil
 }
@@ -330,6 +618,10 @@ func (uc *ReservationUsecase) GetReservationById(ctx context.Context, id uuid.UU
 		return repository.Reservation{}, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Error, err, "failed to get reservation")
 	}
 
+	if err = uc.authorizeReservation(ctx, tx, reservation); err != nil {
+		return repository.Reservation{}, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return repository.Reservation{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to commit get reservation")
 	}
@@ -354,53 +646,47 @@ func (uc *ReservationUsecase) GetReservationById(ctx context.Context, id uuid.UU
 // 	}()
 // }
 
-func (uc *ReservationUsecase) CancelReservation(ctx context.Context, id uuid.UUID) error {
+func (uc *ReservationUsecase) CancelReservation(ctx context.Context, id uuid.UUID) (err error) {
 	tx, err := uc.Repo.BeginTransaction(ctx)
 	if err != nil {
 		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to begin transaction")
 	}
 	defer func() {
 		if err != nil {
-			err := tx.Rollback(ctx)
-			if err != nil {
-				return
-			}
+			tx.Rollback(ctx)
 		}
 	}()
 
-	reservation, err := uc.GetReservationById(ctx, id)
+	reservation, err := tx.GetReservation(ctx, id)
 	if err != nil {
 		return utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed to get reservation / unkown id")
 	}
 
-	if reservation.ReservationStatus == "canceled" {
-		return utils.WrapError(fiber.StatusConflict, uc.Log, utils.Info, nil, "Reservation already canceled")
+	if err = uc.authorizeReservation(ctx, tx, reservation); err != nil {
+		return err
 	}
 
-	err = tx.CancelReservation(ctx, id)
-	if err != nil {
-		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to canceled reservation")
+	if err = uc.transitionReservation(ctx, tx, reservation, repository.StatusReservationCancelled, reasonCancelled); err != nil {
+		return err
 	}
 
-	if err := tx.Commit(ctx); err != nil {
+	if err = tx.Commit(ctx); err != nil {
 		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to commit trancsaction")
 	}
 
 	uc.Log.Info("successfully canceled status reservation")
+	uc.promoteWaitlist(ctx, reservation.ScheduleID)
 	return nil
 }
 
-func (uc *ReservationUsecase) DeleteReservation(ctx context.Context, id uuid.UUID) error {
+func (uc *ReservationUsecase) DeleteReservation(ctx context.Context, id uuid.UUID) (err error) {
 	tx, err := uc.Repo.BeginTransaction(ctx)
 	if err != nil {
 		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to begin transaction")
 	}
 	defer func() {
 		if err != nil {
-			err := tx.Rollback(ctx)
-			if err != nil {
-				return
-			}
+			tx.Rollback(ctx)
 		}
 	}()
 
@@ -409,7 +695,11 @@ func (uc *ReservationUsecase) DeleteReservation(ctx context.Context, id uuid.UUI
 		return utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed unkown id or invalid reservation_id")
 	}
 
-	if reservation.ReservationStatus != "canceled" {
+	if err = uc.authorizeReservation(ctx, tx, reservation); err != nil {
+		return err
+	}
+
+	if reservation.ReservationStatus != repository.StatusReservationCancelled {
 		return fiber.NewError(fiber.StatusBadRequest, "failed reservation status isn't canceled yet ")
 	}
 
@@ -418,49 +708,79 @@ func (uc *ReservationUsecase) DeleteReservation(ctx context.Context, id uuid.UUI
 		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, err.Error())
 	}
 
-	if err := tx.Commit(ctx); err != nil {
+	if err = tx.Commit(ctx); err != nil {
 		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to commit trancsaction")
 	}
 	uc.Log.Info("successfully deleted reservation", zap.Any("id", reservation.ID))
 	return nil
 }
 
-func (uc *ReservationUsecase) AutoDeleteReservations(ctx context.Context) error {
+// AutoDeleteReservations cancels the pending reservations whose payment deadline has passed.
+// The reservations are kept, every expiry goes through the reservation state machine and is
+// recorded in the status history. A reservation paid in the meantime is left alone.
+func (uc *ReservationUsecase) AutoDeleteReservations(ctx context.Context) (err error) {
 	tx, err := uc.Repo.BeginTransaction(ctx)
 	if err != nil {
 		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to begin transaction")
 	}
 	defer func() {
 		if err != nil {
-			err := tx.Rollback(ctx)
-			if err != nil {
-				return
-			}
+			tx.Rollback(ctx)
 		}
 	}()
 
-	if err := tx.ExpireUndpaidReservations(ctx); err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "failed to delete expired reservation")
+	expired, err := tx.ListExpiredReservations(ctx)
+	if err != nil {
+		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to list expired reservations")
 	}
 
-	if err := tx.Commit(ctx); err != nil {
+	scheduleIDs := make(map[int64]bool)
+	for _, reservation := range expired {
+		err = uc.transitionReservation(ctx, tx, reservation, repository.StatusReservationCancelled, reasonExpired)
+		if utils.StatusCode(err, 0) == fiber.StatusConflict {
+			// paid or cancelled since it was listed, nothing left to expire
+			err = nil
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		scheduleIDs[reservation.ScheduleID] = true
+	}
+
+	if err = tx.Commit(ctx); err != nil {
 		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to commit trancsaction")
 	}
 
+	// every schedule that lost a reservation may have passengers waiting for a seat
+	for scheduleID := range scheduleIDs {
+		uc.promoteWaitlist(ctx, scheduleID)
+	}
+
+	return nil
+}
+
+// PurgeSeatLocks deletes the seat locks that expired without being released, left behind by
+// bookings and holds that were abandoned. Backends that expire locks on their own purge nothing.
+func (uc *ReservationUsecase) PurgeSeatLocks(ctx context.Context) error {
+	purged, err := uc.Repo.PurgeExpiredSeatLocks(ctx)
+	if err != nil {
+		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to purge expired seat locks")
+	}
+	if purged > 0 {
+		uc.Log.Info("expired seat locks purged", zap.Int64("locks", purged))
+	}
 	return nil
 }
 
-func (uc *ReservationUsecase) ConfirmReservation(ctx context.Context, id uuid.UUID) error {
+func (uc *ReservationUsecase) ConfirmReservation(ctx context.Context, id uuid.UUID) (err error) {
 	tx, err := uc.Repo.BeginTransaction(ctx)
 	if err != nil {
 		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to begin transaction")
 	}
 	defer func() {
 		if err != nil {
-			err := tx.Rollback(ctx)
-			if err != nil {
-				return
-			}
+			tx.Rollback(ctx)
 		}
 	}()
 
@@ -470,17 +790,11 @@ func (uc *ReservationUsecase) ConfirmReservation(ctx context.Context, id uuid.UU
 		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to get reservation")
 	}
 
-	if reservation.ReservationStatus == "confirmed" {
-		return utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "Reservation already confirmed")
-	}
-
-	err = tx.ConfirmReservation(ctx, id)
-	if err != nil {
-		uc.Log.Warn("failed to confirm reservation", zap.Error(err))
-		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to confirm reservation")
+	if err = uc.transitionReservation(ctx, tx, reservation, repository.StatusReservationSuccess, reasonPaid); err != nil {
+		return err
 	}
 
-	if err := tx.Commit(ctx); err != nil {
+	if err = tx.Commit(ctx); err != nil {
 		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to commit transaction")
 	}
 
@@ -488,63 +802,115 @@ func (uc *ReservationUsecase) ConfirmReservation(ctx context.Context, id uuid.UU
 	return nil
 }
 
-func (uc *ReservationUsecase) GetAllReservations(ctx context.Context) ([]model.ListReservationsResponse, int64, error) {
-	tx, err := uc.Repo.BeginTransaction(ctx)
+// tripStatuses lists the reservation statuses each trip filter of GetMyReservations covers.
+var tripStatuses = map[string][]repository.StatusReservation{
+	"upcoming":  {repository.StatusReservationPending, repository.StatusReservationSuccess},
+	"past":      {repository.StatusReservationSuccess},
+	"cancelled": {repository.StatusReservationCancelled, repository.StatusReservationRefunded},
+}
+
+// GetMyReservations lists the reservations of the passengers owned by the session user, or by
+// the guest session. Upcoming trips are listed soonest first, everything else latest first.
+func (uc *ReservationUsecase) GetMyReservations(ctx context.Context, req model.MyReservationsRequest) ([]model.ListReservationsResponse, model.PageMetaData, error) {
+	if err := uc.Validate.Struct(req); err != nil {
+		return nil, model.PageMetaData{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "validation failed")
+	}
+
+	session, ok := utils.SessionFrom(ctx)
+	if !ok {
+		return nil, model.PageMetaData{}, fiber.NewError(fiber.StatusUnauthorized, "session is required")
+	}
+
+	arg := repository.CountUserReservationsParams{Statuses: []string{}}
+	if session.UserID != nil {
+		arg.UserID = utils.ToPgUUID(*session.UserID)
+	} else {
+		arg.SessionID = &session.ID
+	}
+
+	var err error
+	arg.DepartureFrom, arg.DepartureTo, err = uc.departureRange(req.From, req.To)
 	if err != nil {
-		uc.Log.Warn("failed to begin transaction", zap.Error(err))
-		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, "failed to begin transaction")
+		return nil, model.PageMetaData{}, err
 	}
-	defer func() {
-		if err != nil {
-			err := tx.Rollback(ctx)
-			if err != nil {
-				return
-			}
+
+	statuses := tripStatuses[req.Trip]
+	if req.Status != "" {
+		status := repository.StatusReservation(req.Status)
+		if req.Trip != "" && !slices.Contains(statuses, status) {
+			return nil, model.PageMetaData{}, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("%s reservations are never %s trips", req.Status, req.Trip))
 		}
-	}()
+		statuses = []repository.StatusReservation{status}
+	}
+	for _, status := range statuses {
+		arg.Statuses = append(arg.Statuses, string(status))
+	}
 
-	arg := repository.ListReservationsParams{
-		Limit:  100,
-		Offset: 0,
+	now := time.Now()
+	switch req.Trip {
+	case "upcoming":
+		if !arg.DepartureFrom.Valid || arg.DepartureFrom.Time.Before(now) {
+			arg.DepartureFrom = pgtype.Timestamp{Time: now, Valid: true}
+		}
+	case "past":
+		if !arg.DepartureTo.Valid || arg.DepartureTo.Time.After(now) {
+			arg.DepartureTo = pgtype.Timestamp{Time: now, Valid: true}
+		}
 	}
 
-	reservations, err := tx.ListReservations(ctx, arg)
+	paging := pageOf(req.Page, req.Size)
+	reservations, err := uc.Repo.ListUserReservations(ctx, repository.ListUserReservationsParams{
+		UserID:        arg.UserID,
+		SessionID:     arg.SessionID,
+		Statuses:      arg.Statuses,
+		DepartureFrom: arg.DepartureFrom,
+		DepartureTo:   arg.DepartureTo,
+		Ascending:     req.Trip == "upcoming",
+		PageLimit:     int32(paging.Size),
+		PageOffset:    int32((paging.Page - 1) * paging.Size),
+	})
 	if err != nil {
-		return nil, 0, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to get all reservations")
+		return nil, model.PageMetaData{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to list reservations")
 	}
-	// get total count
-	totalItem, err := tx.CountReservations(ctx)
+
+	paging.TotalItem, err = uc.Repo.CountUserReservations(ctx, arg)
 	if err != nil {
-		return nil, 0, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to count reservations")
+		return nil, model.PageMetaData{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to count reservations")
 	}
+	paging.TotalPage = (paging.TotalItem + int64(paging.Size) - 1) / int64(paging.Size)
 
-	var response []model.ListReservationsResponse
+	response := make([]model.ListReservationsResponse, 0, len(reservations))
 	for _, reservation := range reservations {
-		seatNumber := fmt.Sprintf("Gerbong %d/%s-%d", *reservation.WagonNumber, reservation.SeatRow.SeatRow, *reservation.SeatNumber)
-
-		response = append(response, model.ListReservationsResponse{
-			ReservationID:      reservation.ReservationID,
-			PassengerName:      reservation.PassengerName,
-			PassengerIDNumber:  reservation.PassengerIDNumber,
-			UserEmail:          reservation.UserEmail,
-			DepartureDate:      reservation.DepartureDate,
-			ArrivalDate:        reservation.ArrivalDate,
-			TicketPrice:        reservation.TicketPrice,
-			TrainName:          reservation.TrainName,
-			ClassType:          string(reservation.ClassType.TipeClass), // Extract the string value from NullTipeClass
-			SeatNumber:         seatNumber,
-			BookingDate:        reservation.BookingDate, // Extract the string value from NullTipeClass
-			SourceStation:      reservation.SourceStation,
-			DestinationStation: reservation.DestinationStation,
-			DiscountCode:       reservation.DiscountCode,
-			PaymentAmount:      reservation.PaymentAmount,
-			PaymentMethod:      reservation.PaymentMethod,
-			PaymentStatus:      reservation.PaymentStatus,
-		})
+		response = append(response, toListReservationsResponse(repository.GetFullReservationRow(reservation)))
 	}
+	return response, paging, nil
+}
 
-	if err := tx.Commit(ctx); err != nil {
-		return nil, 0, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to commit transaction")
+// toListReservationsResponse flattens a joined reservation row into its API shape.
+func toListReservationsResponse(reservation repository.GetFullReservationRow) model.ListReservationsResponse {
+	seatNumber := fmt.Sprintf("Gerbong %d/%s-%d", *reservation.WagonNumber, reservation.SeatRow.SeatRow, *reservation.SeatNumber)
+
+	return model.ListReservationsResponse{
+		ReservationID:      reservation.ReservationID,
+		BookingCode:        reservation.BookingCode,
+		PassengerName:      reservation.PassengerName,
+		PassengerIDNumber:  reservation.PassengerIDNumber,
+		UserName:           reservation.UserName,
+		UserEmail:          reservation.UserEmail,
+		DepartureDate:      reservation.DepartureDate,
+		ArrivalDate:        reservation.ArrivalDate,
+		TicketPrice:        reservation.TicketPrice,
+		TrainName:          reservation.TrainName,
+		ClassType:          string(reservation.ClassType.TipeClass), // Extract the string value from NullTipeClass
+		SeatNumber:         seatNumber,
+		BookingDate:        reservation.BookingDate,
+		ReservationStatus:  string(reservation.ReservationStatus),
+		SourceStation:      reservation.SourceStation,
+		DestinationStation: reservation.DestinationStation,
+		DiscountCode:       reservation.DiscountCode,
+		DiscountPercent:    reservation.DiscountPercent,
+		PaymentAmount:      reservation.PaymentAmount,
+		PaymentMethod:      reservation.PaymentMethod,
+		PaymentStatus:      reservation.PaymentStatus,
 	}
-	return response, totalItem, nil
 }
@@ -2,9 +2,11 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"railway-go/internal/constant/model"
 	"railway-go/internal/repository"
 	"railway-go/internal/utils"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -16,6 +18,8 @@ type RouteUC interface {
 	CreateRoute(ctx context.Context, request model.RouteRequest) (model.Route, error)
 	UpdateRoute(ctx context.Context, id int64, request model.RouteRequest) error
 	DeleteRoute(ctx context.Context, id int64) error
+	GetRouteStops(ctx context.Context, id int64) ([]model.RouteStop, error)
+	SetRouteStops(ctx context.Context, id int64, request model.RouteStopsRequest) ([]model.RouteStop, error)
 }
 
 type RouteUsecase struct {
@@ -194,3 +198,208 @@ func (uc *RouteUsecase) DeleteRoute(ctx context.Context, id int64) error {
 	uc.Log.Info("route deleted successfully", zap.Int64("id", r.ID))
 	return nil
 }
+
+// GetRouteStops lists the stations a route calls at in order, including its source and destination.
+func (uc *RouteUsecase) GetRouteStops(ctx context.Context, id int64) (response []model.RouteStop, err error) {
+	tx, err := uc.Repo.BeginTransaction(ctx)
+	if err != nil {
+		return nil, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to begin transaction")
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	route, err := tx.GetRoute(ctx, id)
+	if err != nil {
+		return nil, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed to get route")
+	}
+
+	stops, err := uc.routeStops(ctx, tx, route)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to commit transaction")
+	}
+
+	return stops, nil
+}
+
+// SetRouteStops replaces the stops of a route. The first and last stop must be the route's
+// source and destination station. Stops cannot change while the route has active reservations,
+// since those reservations refer to stops by position.
+func (uc *RouteUsecase) SetRouteStops(ctx context.Context, id int64, request model.RouteStopsRequest) (response []model.RouteStop, err error) {
+	if err := uc.Validate.Struct(request); err != nil {
+		return nil, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "validation failed")
+	}
+
+	tx, err := uc.Repo.BeginTransaction(ctx)
+	if err != nil {
+		return nil, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to begin transaction")
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	route, err := tx.GetRoute(ctx, id)
+	if err != nil {
+		return nil, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed to get route")
+	}
+
+	stops := request.Stops
+	if stops[0].StationCode != route.SourceStation || stops[len(stops)-1].StationCode != route.DestinationStation {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "stops must start at the source station and end at the destination station of the route")
+	}
+
+	seen := make(map[string]bool, len(stops))
+	for i, stop := range stops {
+		if seen[stop.StationCode] {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("station %s is listed more than once", stop.StationCode))
+		}
+		seen[stop.StationCode] = true
+
+		if i > 0 && stop.MinutesFromStart < stops[i-1].MinutesFromStart {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "minutes_from_start must not decrease along the route")
+		}
+	}
+
+	active, err := tx.CountActiveRouteReservations(ctx, route.ID)
+	if err != nil {
+		return nil, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to count route reservations")
+	}
+	if active > 0 {
+		return nil, fiber.NewError(fiber.StatusConflict, "route has active reservations, stops cannot be changed")
+	}
+
+	if err = tx.DeleteRouteStops(ctx, route.ID); err != nil {
+		return nil, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to clear route stops")
+	}
+
+	for i, stop := range stops {
+		created, err := tx.CreateRouteStop(ctx, repository.CreateRouteStopParams{
+			RouteID:          route.ID,
+			StopOrder:        int32(i),
+			StationCode:      stop.StationCode,
+			MinutesFromStart: stop.MinutesFromStart,
+		})
+		if err != nil {
+			return nil, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, fmt.Sprintf("failed to add stop %s", stop.StationCode))
+		}
+		response = append(response, model.RouteStop{
+			StopOrder:        created.StopOrder,
+			StationCode:      created.StationCode,
+			MinutesFromStart: created.MinutesFromStart,
+		})
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to commit transaction")
+	}
+
+	uc.Log.Info("route stops updated", zap.Int64("route_id", route.ID), zap.Int("stops", len(response)))
+	return response, nil
+}
+
+// routeStops returns the stops of a route in order. A route without stops is served
+// as a single leg from its source to its destination station.
+func (uc *UseCase) routeStops(ctx context.Context, tx repository.Querier, route repository.Route) ([]model.RouteStop, error) {
+	stops, err := tx.ListRouteStops(ctx, route.ID)
+	if err != nil {
+		return nil, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to list route stops")
+	}
+	return toRouteStops(route, stops), nil
+}
+
+// toRouteStops maps the stops loaded for a route, see routeStops.
+func toRouteStops(route repository.Route, stops []repository.RouteStop) []model.RouteStop {
+	if len(stops) == 0 {
+		return []model.RouteStop{
+			{StopOrder: 0, StationCode: route.SourceStation},
+			{StopOrder: 1, StationCode: route.DestinationStation, MinutesFromStart: route.TravelTime},
+		}
+	}
+
+	response := make([]model.RouteStop, 0, len(stops))
+	for _, stop := range stops {
+		response = append(response, model.RouteStop{
+			StopOrder:        stop.StopOrder,
+			StationCode:      stop.StationCode,
+			MinutesFromStart: stop.MinutesFromStart,
+		})
+	}
+	return response
+}
+
+// journeySegment is the range of legs [From, To) of a schedule's route that a reservation occupies.
+// DepartMinutes and ArriveMinutes are the offsets of its boarding and alighting stops from the
+// schedule's departure.
+type journeySegment struct {
+	From          int32
+	To            int32
+	Legs          int32
+	DepartMinutes int32
+	ArriveMinutes int32
+}
+
+// fare prorates the full route price to the legs covered by the segment.
+func (s journeySegment) fare(price int64) int64 {
+	return price * int64(s.To-s.From) / int64(s.Legs)
+}
+
+// departure is the time the schedule leaves the boarding stop of the segment.
+func (s journeySegment) departure(schedule repository.Schedule) time.Time {
+	return schedule.DepartureDate.Time.Add(time.Duration(s.DepartMinutes) * time.Minute)
+}
+
+// arrival is the time the schedule reaches the alighting stop of the segment.
+func (s journeySegment) arrival(schedule repository.Schedule) time.Time {
+	return schedule.DepartureDate.Time.Add(time.Duration(s.ArriveMinutes) * time.Minute)
+}
+
+// resolveSegment maps the boarding and alighting station codes onto the stops of the
+// schedule's route. An empty code stands for the first or last stop respectively,
+// so leaving both empty covers the whole route.
+func (uc *UseCase) resolveSegment(ctx context.Context, tx repository.Querier, schedule repository.Schedule, from, to string) (journeySegment, error) {
+	route, err := tx.GetRoute(ctx, schedule.RouteID)
+	if err != nil {
+		return journeySegment{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to get schedule route")
+	}
+
+	stops, err := uc.routeStops(ctx, tx, route)
+	if err != nil {
+		return journeySegment{}, err
+	}
+
+	stopOrder := make(map[string]int32, len(stops))
+	for _, stop := range stops {
+		stopOrder[stop.StationCode] = stop.StopOrder
+	}
+
+	segment := journeySegment{From: 0, To: int32(len(stops) - 1), Legs: int32(len(stops) - 1)}
+	if from != "" {
+		order, ok := stopOrder[from]
+		if !ok {
+			return journeySegment{}, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("station %s is not a stop of this schedule", from))
+		}
+		segment.From = order
+	}
+	if to != "" {
+		order, ok := stopOrder[to]
+		if !ok {
+			return journeySegment{}, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("station %s is not a stop of this schedule", to))
+		}
+		segment.To = order
+	}
+	if segment.From >= segment.To {
+		return journeySegment{}, fiber.NewError(fiber.StatusBadRequest, "the destination station must come after the departure station")
+	}
+	segment.DepartMinutes = stops[segment.From].MinutesFromStart
+	segment.ArriveMinutes = stops[segment.To].MinutesFromStart
+
+	return segment, nil
+}
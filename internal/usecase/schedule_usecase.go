@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"railway-go/internal/constant/model"
 	"railway-go/internal/repository"
 	"railway-go/internal/utils"
@@ -18,6 +19,8 @@ type ScheduleUC interface {
 	GetSchedule(ctx context.Context, id int64) (repository.Schedule, error)
 	DeleteSchedule(ctx context.Context, id int64) error
 	SearchSchedules(ctx context.Context, request *model.SearchScheduleRequest) ([]model.SearchScheduleResponse, error)
+	SearchItinerary(ctx context.Context, request *model.ItinerarySearchRequest) (model.ItinerarySearchResponse, error)
+	GetSeatMap(ctx context.Context, id int64, from, to string) (model.SeatMapResponse, error)
 }
 
 type ScheduleUsecase struct {
@@ -67,12 +70,11 @@ func (uc *ScheduleUsecase) CreateSchedule(ctx context.Context, request *model.Sc
 	}
 
 	schedule := repository.CreateScheduleParams{
-		TrainID:        train.ID,
-		DepartureDate:  request.DepartureDate,
-		ArrivalDate:    *arrival,
-		AvailableSeats: request.AvailableSeats,
-		Price:          request.Price,
-		RouteID:        route.ID,
+		TrainID:       train.ID,
+		DepartureDate: request.DepartureDate,
+		ArrivalDate:   *arrival,
+		Price:         request.Price,
+		RouteID:       route.ID,
 	}
 
 	response, err := tx.CreateSchedule(ctx, schedule)
@@ -133,13 +135,12 @@ func (uc *ScheduleUsecase) UpdateSchedule(ctx context.Context, reqId int64, requ
 	}
 
 	schedule := repository.UpdateScheduleParams{
-		ID:             s.ID,
-		TrainID:        train.ID,
-		RouteID:        route.ID,
-		DepartureDate:  request.DepartureDate,
-		ArrivalDate:    *arrival,
-		Price:          request.Price,
-		AvailableSeats: request.AvailableSeats,
+		ID:            s.ID,
+		TrainID:       train.ID,
+		RouteID:       route.ID,
+		DepartureDate: request.DepartureDate,
+		ArrivalDate:   *arrival,
+		Price:         request.Price,
 	}
 
 	if err := tx.UpdateSchedule(ctx, schedule); err != nil {
@@ -247,16 +248,49 @@ func (uc *ScheduleUsecase) SearchSchedules(ctx context.Context, request *model.S
 		return nil, fiber.NewError(fiber.StatusNotFound, "failed to search schedule")
 	}
 
+	ids := make([]int64, 0, len(response))
+	for _, schedule := range response {
+		ids = append(ids, schedule.ScheduleID)
+	}
+
+	// seats left per class, counted from seats minus pending and paid reservations
+	availability, err := tx.ListScheduleAvailability(ctx, ids)
+	if err != nil {
+		return nil, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to count available seats")
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		uc.Log.Error("faield to commit transaction", zap.Error(err))
 		return nil, fiber.ErrInternalServerError
 	}
 
+	// the search matches the ends of the routes, so every schedule is priced for the whole route
+	prices := make(map[int64]repository.Schedule, len(response))
+	for _, schedule := range response {
+		prices[schedule.ScheduleID] = repository.Schedule{ID: schedule.ScheduleID, DepartureDate: schedule.DepartureDate, ArrivalDate: schedule.ArrivalDate, Price: schedule.Price}
+	}
+	wholeRoute := journeySegment{From: 0, To: 1, Legs: 1}
+
+	classes := make(map[int64][]model.ClassAvailability, len(ids))
+	for _, row := range availability {
+		classes[row.ScheduleID] = append(classes[row.ScheduleID], model.ClassAvailability{
+			ClassType:      string(row.ClassType),
+			TotalSeats:     row.TotalSeats,
+			AvailableSeats: row.AvailableSeats,
+			Price:          uc.fare(prices[row.ScheduleID], row.ClassType, wholeRoute, model.DefaultPassengerType, 0).Total,
+		})
+	}
+
 	// map response to model
 	var schedules []model.SearchScheduleResponse
 	for _, schedule := range response {
 		// return only future schedules
 		if schedule.DepartureDate.Time.After(time.Now()) {
+			var available int64
+			for _, class := range classes[schedule.ScheduleID] {
+				available += class.AvailableSeats
+			}
+
 			schedules = append(schedules, model.SearchScheduleResponse{
 				ScheduleID:         schedule.ScheduleID,
 				TrainName:          schedule.TrainName,
@@ -264,11 +298,198 @@ func (uc *ScheduleUsecase) SearchSchedules(ctx context.Context, request *model.S
 				DestinationStation: schedule.DestinationStation,
 				DepartureDate:      schedule.DepartureDate,
 				ArrivalDate:        schedule.ArrivalDate,
-				AvailableSeats:     schedule.AvailableSeats,
-				Price:              schedule.Price,
+				AvailableSeats:     available,
+				Classes:            classes[schedule.ScheduleID],
+				Price:              uc.searchPrice(prices[schedule.ScheduleID], classes[schedule.ScheduleID], wholeRoute),
 			})
 		}
 	}
 
 	return schedules, nil
 }
+
+// searchPrice is the lowest fare of the schedule, over the classes with seats left when any has.
+// A schedule without classes is priced in the cheapest class of the fare rules.
+func (uc *ScheduleUsecase) searchPrice(schedule repository.Schedule, classes []model.ClassAvailability, segment journeySegment) int64 {
+	if len(classes) == 0 {
+		return uc.cheapestFare(schedule, segment)
+	}
+
+	price, seatsLeft := classes[0].Price, classes[0].AvailableSeats > 0
+	for _, class := range classes[1:] {
+		switch {
+		case class.AvailableSeats > 0 && (!seatsLeft || class.Price < price):
+			price, seatsLeft = class.Price, true
+		case !seatsLeft && class.Price < price:
+			price = class.Price
+		}
+	}
+	return price
+}
+
+// GetSeatMap lists every seat of the schedule's train grouped by wagon, together with its current state
+// for the legs between the from and to stations (the whole route when both are empty):
+//   - blocked: the seat is taken out of service (is_available = false)
+//   - booked: a paid reservation overlaps the legs
+//   - held: a pending reservation overlaps the legs, or a seat lock holds the seat
+//   - free: none of the above
+func (uc *ScheduleUsecase) GetSeatMap(ctx context.Context, id int64, from, to string) (response model.SeatMapResponse, err error) {
+	tx, err := uc.Repo.BeginTransaction(ctx)
+	if err != nil {
+		return model.SeatMapResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to begin transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	schedule, err := tx.GetSchedule(ctx, id)
+	if err != nil {
+		return model.SeatMapResponse{}, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed to find schedule")
+	}
+
+	segment, err := uc.resolveSegment(ctx, tx, schedule, from, to)
+	if err != nil {
+		return model.SeatMapResponse{}, err
+	}
+
+	rows, err := tx.ListScheduleSeats(ctx, repository.ListScheduleSeatsParams{
+		FromStop:   segment.From,
+		ToStop:     segment.To,
+		ScheduleID: schedule.ID,
+	})
+	if err != nil {
+		return model.SeatMapResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to list schedule seats")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.SeatMapResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to commit transaction")
+	}
+
+	refs := make([]repository.SeatRef, 0, len(rows))
+	for _, row := range rows {
+		refs = append(refs, repository.SeatRef{WagonID: row.WagonID, SeatID: row.SeatID})
+	}
+
+	locked, err := uc.Repo.LockedSeats(ctx, schedule.ID, refs)
+	if err != nil {
+		return model.SeatMapResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to look up seat locks")
+	}
+
+	response = model.SeatMapResponse{ScheduleID: schedule.ID, FromStop: segment.From, ToStop: segment.To, Wagons: []model.SeatMapWagon{}}
+	for _, row := range rows {
+		ref := repository.SeatRef{WagonID: row.WagonID, SeatID: row.SeatID}
+		if n := len(response.Wagons); n == 0 || response.Wagons[n-1].WagonID != row.WagonID {
+			response.Wagons = append(response.Wagons, model.SeatMapWagon{
+				WagonID:     row.WagonID,
+				WagonNumber: row.WagonNumber,
+				ClassType:   string(row.ClassType),
+			})
+		}
+		wagon := &response.Wagons[len(response.Wagons)-1]
+		wagon.Seats = append(wagon.Seats, model.SeatMapSeat{
+			SeatID:     row.SeatID,
+			SeatNumber: row.SeatNumber,
+			SeatRow:    string(row.SeatRow),
+			State:      seatState(row, locked[ref]),
+		})
+	}
+
+	return response, nil
+}
+
+func seatState(row repository.ListScheduleSeatsRow, locked bool) string {
+	switch {
+	case row.IsAvailable != nil && !*row.IsAvailable:
+		return model.SeatStateBlocked
+	case row.Paid:
+		return model.SeatStateBooked
+	case row.ActiveReservations > 0 || locked:
+		return model.SeatStateHeld
+	default:
+		return model.SeatStateFree
+	}
+}
+
+// SearchItinerary searches the schedules of every leg and finds the cheapest combination
+// in which each schedule departs after the previous one arrives.
+func (uc *ScheduleUsecase) SearchItinerary(ctx context.Context, request *model.ItinerarySearchRequest) (model.ItinerarySearchResponse, error) {
+	if err := uc.Validate.Struct(request); err != nil {
+		return model.ItinerarySearchResponse{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "validation failed")
+	}
+
+	for i := 1; i < len(request.Legs); i++ {
+		if request.Legs[i].DepartureDate < request.Legs[i-1].DepartureDate {
+			return model.ItinerarySearchResponse{}, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("leg %d departs before leg %d", i+1, i))
+		}
+	}
+
+	response := model.ItinerarySearchResponse{Legs: make([]model.ItineraryLegOptions, 0, len(request.Legs))}
+	for i := range request.Legs {
+		schedules, err := uc.SearchSchedules(ctx, &request.Legs[i])
+		if err != nil {
+			return model.ItinerarySearchResponse{}, err
+		}
+		if schedules == nil {
+			schedules = []model.SearchScheduleResponse{}
+		}
+		response.Legs = append(response.Legs, model.ItineraryLegOptions{Leg: i + 1, Schedules: schedules})
+	}
+
+	response.CheapestTotal, response.CheapestScheduleIDs = cheapestItinerary(response.Legs)
+	return response, nil
+}
+
+// cheapestItinerary picks one schedule per leg with the lowest total price, where every
+// schedule departs after the previous one arrives. Sold out schedules are left out, and
+// schedules are compared leg by leg keeping the cheapest way to reach each schedule of the current leg.
+func cheapestItinerary(legs []model.ItineraryLegOptions) (*int64, []int64) {
+	type path struct {
+		total     int64
+		arrival   time.Time
+		schedules []int64
+	}
+
+	var paths []path
+	for i, leg := range legs {
+		var next []path
+		for _, schedule := range leg.Schedules {
+			if schedule.AvailableSeats == 0 {
+				continue
+			}
+			if i == 0 {
+				next = append(next, path{schedule.Price, schedule.ArrivalDate.Time, []int64{schedule.ScheduleID}})
+				continue
+			}
+
+			best := -1
+			for j, prev := range paths {
+				if prev.arrival.After(schedule.DepartureDate.Time) {
+					continue
+				}
+				if best < 0 || prev.total < paths[best].total {
+					best = j
+				}
+			}
+			if best < 0 {
+				continue
+			}
+			ids := append(append([]int64{}, paths[best].schedules...), schedule.ScheduleID)
+			next = append(next, path{paths[best].total + schedule.Price, schedule.ArrivalDate.Time, ids})
+		}
+		paths = next
+	}
+
+	if len(paths) == 0 {
+		return nil, []int64{}
+	}
+	cheapest := paths[0]
+	for _, p := range paths[1:] {
+		if p.total < cheapest.total {
+			cheapest = p
+		}
+	}
+	return &cheapest.total, cheapest.schedules
+}
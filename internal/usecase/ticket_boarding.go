@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"railway-go/internal/constant/model"
+	"railway-go/internal/repository"
+	"railway-go/internal/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const (
+	// checkInWindow is how long before the departure from the boarding station check-in opens.
+	checkInWindow = 24 * time.Hour
+	// noShowGraceMinutes leaves conductors time to scan late boarders before a ticket is a no-show.
+	noShowGraceMinutes = 30
+)
+
+// CheckInTicket checks the passenger of a paid reservation in for the trip. Check-in opens
+// a day before the train leaves the boarding station and closes when it departs.
+func (uc *TicketUsecase) CheckInTicket(ctx context.Context, id uuid.UUID) (model.CheckInResponse, error) {
+	reservation, err := uc.paidReservation(ctx, id)
+	if err != nil {
+		return model.CheckInResponse{}, err
+	}
+
+	issued, err := uc.ticket(ctx, reservation)
+	if err != nil {
+		return model.CheckInResponse{}, err
+	}
+	now := time.Now()
+	if now.Before(issued.DepartureDate.Add(-checkInWindow)) {
+		return model.CheckInResponse{}, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("check-in opens at %s", issued.DepartureDate.Add(-checkInWindow).Format(time.RFC3339)))
+	}
+	if !now.Before(issued.DepartureDate) {
+		return model.CheckInResponse{}, fiber.NewError(fiber.StatusConflict, "check-in closed when the train departed")
+	}
+
+	boarding, err := uc.Repo.CheckInTicket(ctx, repository.CheckInTicketParams{
+		ReservationID: reservation.ID,
+		ScheduleID:    reservation.ScheduleID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		existing, getErr := uc.Repo.GetTicketBoarding(ctx, reservation.ID)
+		if getErr != nil {
+			return model.CheckInResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, getErr, "failed to get boarding")
+		}
+		return model.CheckInResponse{}, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("ticket is already %s", existing.Status))
+	}
+	if err != nil {
+		return model.CheckInResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to check in")
+	}
+
+	return model.CheckInResponse{Ticket: issued, CheckedInAt: boarding.CheckedInAt.Time}, nil
+}
+
+// AutoMarkNoShows marks every paid ticket that was not scanned on board as a no-show, once
+// the train left the boarding station of the ticket and the grace period passed. Trips departing
+// before the no-show cutoff are left alone.
+func (uc *TicketUsecase) AutoMarkNoShows(ctx context.Context) error {
+	marked, err := uc.Repo.MarkNoShows(ctx, repository.MarkNoShowsParams{
+		GraceMinutes:  noShowGraceMinutes,
+		DepartedAfter: pgtype.Timestamp{Time: uc.noShowCutoff, Valid: true},
+	})
+	if err != nil {
+		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to mark no-shows")
+	}
+	if len(marked) > 0 {
+		uc.Log.Info("marked no-shows", zap.Int("tickets", len(marked)))
+	}
+	return nil
+}
+
+// GetNoShowReport reports the no-show rate of every route over the travelled tickets,
+// for trips departing between the requested dates.
+func (uc *TicketUsecase) GetNoShowReport(ctx context.Context, req model.NoShowReportRequest) ([]model.NoShowReportResponse, error) {
+	if err := uc.Validate.Struct(req); err != nil {
+		return nil, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "validation failed")
+	}
+
+	from, to, err := uc.departureRange(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	arg := repository.NoShowReportParams{DepartureFrom: from, DepartureTo: to}
+	if req.RouteID != 0 {
+		arg.RouteID = &req.RouteID
+	}
+
+	rows, err := uc.Repo.NoShowReport(ctx, arg)
+	if err != nil {
+		return nil, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to get no-show report")
+	}
+
+	report := make([]model.NoShowReportResponse, 0, len(rows))
+	for _, row := range rows {
+		entry := model.NoShowReportResponse{
+			RouteID:            row.RouteID,
+			SourceStation:      row.SourceStation,
+			DestinationStation: row.DestinationStation,
+			Tickets:            row.Tickets,
+			Boarded:            row.Boarded,
+			NoShows:            row.NoShows,
+			CheckedIn:          row.CheckedIn,
+			CheckedInNoShows:   row.CheckedInNoShows,
+		}
+		if row.Tickets > 0 {
+			entry.NoShowRate = float64(row.NoShows) / float64(row.Tickets)
+		}
+		report = append(report, entry)
+	}
+	return report, nil
+}
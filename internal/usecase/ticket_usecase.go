@@ -0,0 +1,289 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"railway-go/internal/constant/model"
+	"railway-go/internal/repository"
+	"railway-go/internal/utils"
+	"railway-go/internal/utils/ticket"
+	"railway-go/internal/utils/token"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// ticketGracePeriod keeps a ticket token valid for a while after the train arrived.
+const ticketGracePeriod = 6 * time.Hour
+
+type TicketUC interface {
+	GetTicketPDF(ctx context.Context, id uuid.UUID) ([]byte, error)
+	GetTicketToken(ctx context.Context, id uuid.UUID) (model.TicketTokenResponse, error)
+	BoardTicket(ctx context.Context, req model.BoardingRequest) (model.BoardingResponse, error)
+	GetTicketPublicKey() model.TicketPublicKeyResponse
+	CheckInTicket(ctx context.Context, id uuid.UUID) (model.CheckInResponse, error)
+	AutoMarkNoShows(ctx context.Context) error
+	GetNoShowReport(ctx context.Context, req model.NoShowReportRequest) ([]model.NoShowReportResponse, error)
+}
+
+type TicketUsecase struct {
+	*UseCase
+	signer token.TicketSigner
+	// noShowCutoff is the earliest departure marked as a no-show, older trips were travelled
+	// before boarding was scanned and have no boarding to tell a no-show apart.
+	noShowCutoff time.Time
+}
+
+// NewTicketUsecase reads the no-show cutoff from no_show.departed_after as a YYYY-MM-DD date.
+// Without one only trips departing after the service started are marked.
+func NewTicketUsecase(useCase *UseCase, signer token.TicketSigner, config *viper.Viper) TicketUC {
+	cutoff := time.Now()
+	if value := config.GetString("no_show.departed_after"); value != "" {
+		day, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			useCase.Log.Warn("invalid no-show cutoff, only trips departing from now on are marked", zap.Error(err))
+		} else {
+			cutoff = day
+		}
+	} else {
+		useCase.Log.Warn("no no-show cutoff configured, only trips departing from now on are marked")
+	}
+
+	return &TicketUsecase{UseCase: useCase, signer: signer, noShowCutoff: cutoff}
+}
+
+// GetTicketPDF renders the e-ticket of a paid reservation, its QR code holds the signed ticket token.
+func (uc *TicketUsecase) GetTicketPDF(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	reservation, err := uc.paidReservation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	issued, err := uc.ticket(ctx, reservation)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf, err := ticket.RenderPDF(issued)
+	if err != nil {
+		return nil, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to render ticket")
+	}
+	return pdf, nil
+}
+
+// GetTicketToken returns the signed ticket token of a paid reservation, as printed in the QR code.
+func (uc *TicketUsecase) GetTicketToken(ctx context.Context, id uuid.UUID) (model.TicketTokenResponse, error) {
+	reservation, err := uc.paidReservation(ctx, id)
+	if err != nil {
+		return model.TicketTokenResponse{}, err
+	}
+
+	schedule, err := uc.Repo.GetSchedule(ctx, reservation.ScheduleID)
+	if err != nil {
+		return model.TicketTokenResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to get schedule")
+	}
+
+	signed, payload, err := uc.signTicket(reservation, schedule)
+	if err != nil {
+		return model.TicketTokenResponse{}, err
+	}
+	return model.TicketTokenResponse{Token: signed, ExpiresAt: payload.ExpiredAt}, nil
+}
+
+// BoardTicket checks a scanned ticket token on board of a schedule and records the boarding.
+// The token must carry a valid signature, be issued for the schedule and still match a paid
+// reservation. Every ticket boards once, a second scan is reported as a conflict. Boarding
+// completes a check-in and overrides a no-show marked before the ticket was scanned.
+func (uc *TicketUsecase) BoardTicket(ctx context.Context, req model.BoardingRequest) (response model.BoardingResponse, err error) {
+	if err := uc.Validate.Struct(req); err != nil {
+		return model.BoardingResponse{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "validation failed")
+	}
+
+	payload, err := uc.signer.VerifyTicket(req.Token)
+	if err != nil {
+		if errors.Is(err, model.ErrExpiredToken) {
+			return model.BoardingResponse{}, fiber.NewError(fiber.StatusBadRequest, "ticket has expired")
+		}
+		uc.Log.Warn("invalid ticket scanned", zap.String("actor", utils.ActorFrom(ctx)), zap.Int64("schedule_id", req.ScheduleID))
+		return model.BoardingResponse{}, fiber.NewError(fiber.StatusBadRequest, "ticket is not valid")
+	}
+	if payload.ScheduleID != req.ScheduleID {
+		return model.BoardingResponse{}, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("ticket is for schedule %d", payload.ScheduleID))
+	}
+
+	tx, err := uc.Repo.BeginTransaction(ctx)
+	if err != nil {
+		return model.BoardingResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to begin transaction")
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	reservation, err := tx.GetReservation(ctx, payload.ReservationID)
+	if err != nil {
+		return model.BoardingResponse{}, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed to get reservation")
+	}
+	if reservation.ReservationStatus != repository.StatusReservationSuccess {
+		return model.BoardingResponse{}, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("ticket belongs to a %s reservation", reservation.ReservationStatus))
+	}
+	if reservation.ScheduleID != payload.ScheduleID || reservation.WagonID != payload.WagonID || reservation.SeatID != payload.SeatID ||
+		reservation.FromStop != payload.FromStop || reservation.ToStop != payload.ToStop {
+		return model.BoardingResponse{}, fiber.NewError(fiber.StatusConflict, "ticket no longer matches the reservation")
+	}
+
+	arg := repository.BoardTicketParams{
+		ReservationID: reservation.ID,
+		ScheduleID:    reservation.ScheduleID,
+	}
+	if session, ok := utils.SessionFrom(ctx); ok && session.UserID != nil {
+		arg.ConductorID = utils.ToPgUUID(*session.UserID)
+	}
+	boarding, err := tx.BoardTicket(ctx, arg)
+	if errors.Is(err, pgx.ErrNoRows) {
+		boarded, getErr := tx.GetTicketBoarding(ctx, reservation.ID)
+		if getErr != nil {
+			return model.BoardingResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, getErr, "failed to get boarding")
+		}
+		return model.BoardingResponse{}, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("ticket was already scanned at %s", boarded.BoardedAt.Time.Format(time.RFC3339)))
+	}
+	if err != nil {
+		return model.BoardingResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to record boarding")
+	}
+
+	issued, err := uc.ticket(ctx, reservation)
+	if err != nil {
+		return model.BoardingResponse{}, err
+	}
+	issued.QRCode = ""
+
+	if err = tx.Commit(ctx); err != nil {
+		return model.BoardingResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to commit transaction")
+	}
+
+	uc.Log.Info("ticket boarded", zap.String("reservation_id", reservation.ID.String()), zap.String("actor", utils.ActorFrom(ctx)))
+	return model.BoardingResponse{Ticket: issued, BoardedAt: boarding.BoardedAt.Time}, nil
+}
+
+// GetTicketPublicKey returns the key conductor devices verify ticket tokens with.
+func (uc *TicketUsecase) GetTicketPublicKey() model.TicketPublicKeyResponse {
+	return model.TicketPublicKeyResponse{
+		Algorithm: "ed25519",
+		Format:    "paseto v2.public",
+		PublicKey: base64.StdEncoding.EncodeToString(uc.signer.PublicKey()),
+	}
+}
+
+// paidReservation loads a reservation of the session owner that has a ticket, only paid reservations do.
+func (uc *TicketUsecase) paidReservation(ctx context.Context, id uuid.UUID) (repository.Reservation, error) {
+	reservation, err := uc.Repo.GetReservation(ctx, id)
+	if err != nil {
+		return repository.Reservation{}, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed to get reservation")
+	}
+	if err := uc.authorizeReservation(ctx, uc.Repo, reservation); err != nil {
+		return repository.Reservation{}, err
+	}
+	if reservation.ReservationStatus != repository.StatusReservationSuccess {
+		return repository.Reservation{}, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("a %s reservation has no ticket, only paid reservations do", reservation.ReservationStatus))
+	}
+	return reservation, nil
+}
+
+// signTicket issues the ticket token of the reservation, valid until a while after arrival.
+func (uc *TicketUsecase) signTicket(reservation repository.Reservation, schedule repository.Schedule) (string, token.TicketPayload, error) {
+	payload := token.TicketPayload{
+		ReservationID: reservation.ID,
+		ScheduleID:    reservation.ScheduleID,
+		WagonID:       reservation.WagonID,
+		SeatID:        reservation.SeatID,
+		FromStop:      reservation.FromStop,
+		ToStop:        reservation.ToStop,
+		IssuedAt:      time.Now(),
+		ExpiredAt:     schedule.ArrivalDate.Time.Add(ticketGracePeriod),
+	}
+
+	signed, err := uc.signer.SignTicket(payload)
+	if err != nil {
+		return "", token.TicketPayload{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to sign ticket")
+	}
+	return signed, payload, nil
+}
+
+// ticket collects what is printed on the ticket of the reservation, for the stops the passenger booked.
+func (uc *TicketUsecase) ticket(ctx context.Context, reservation repository.Reservation) (model.Ticket, error) {
+	full, err := uc.Repo.GetFullReservation(ctx, reservation.ID)
+	if err != nil {
+		return model.Ticket{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to get reservation")
+	}
+	schedule, err := uc.Repo.GetSchedule(ctx, reservation.ScheduleID)
+	if err != nil {
+		return model.Ticket{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to get schedule")
+	}
+	route, err := uc.Repo.GetRoute(ctx, schedule.RouteID)
+	if err != nil {
+		return model.Ticket{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to get route")
+	}
+	stops, err := uc.routeStops(ctx, uc.Repo, route)
+	if err != nil {
+		return model.Ticket{}, err
+	}
+	if reservation.FromStop < 0 || int(reservation.ToStop) >= len(stops) {
+		return model.Ticket{}, fiber.NewError(fiber.StatusInternalServerError, "reservation stops do not match the route")
+	}
+
+	from, err := uc.ticketStation(ctx, stops[reservation.FromStop].StationCode)
+	if err != nil {
+		return model.Ticket{}, err
+	}
+	to, err := uc.ticketStation(ctx, stops[reservation.ToStop].StationCode)
+	if err != nil {
+		return model.Ticket{}, err
+	}
+
+	// the schedule times are exact at the route ends, intermediate stops follow the timetable
+	departure := schedule.DepartureDate.Time.Add(time.Duration(stops[reservation.FromStop].MinutesFromStart) * time.Minute)
+	arrival := schedule.DepartureDate.Time.Add(time.Duration(stops[reservation.ToStop].MinutesFromStart) * time.Minute)
+	if int(reservation.ToStop) == len(stops)-1 {
+		arrival = schedule.ArrivalDate.Time
+	}
+
+	signed, _, err := uc.signTicket(reservation, schedule)
+	if err != nil {
+		return model.Ticket{}, err
+	}
+
+	listed := toListReservationsResponse(full)
+	issued := model.Ticket{
+		ReservationID:     reservation.ID,
+		BookingCode:       full.BookingCode,
+		PassengerName:     deref(full.PassengerName),
+		PassengerIDNumber: deref(full.PassengerIDNumber),
+		TrainName:         deref(full.TrainName),
+		ClassType:         listed.ClassType,
+		SeatNumber:        listed.SeatNumber,
+		From:              from,
+		To:                to,
+		DepartureDate:     departure,
+		ArrivalDate:       arrival,
+		QRCode:            signed,
+	}
+	if reservation.Price != nil {
+		issued.Price = *reservation.Price
+	}
+	return issued, nil
+}
+
+func (uc *TicketUsecase) ticketStation(ctx context.Context, code string) (model.TicketStation, error) {
+	station, err := uc.Repo.GetStationByCode(ctx, code)
+	if err != nil {
+		return model.TicketStation{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, fmt.Sprintf("failed to get station %s", code))
+	}
+	return model.TicketStation{Code: station.Code, Name: station.StationName}, nil
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"railway-go/internal/constant/model"
 	"railway-go/internal/repository"
 
 	"github.com/go-playground/validator/v10"
@@ -11,16 +12,19 @@ type UseCase struct {
 	Repo     repository.Store
 	Log      *zap.Logger
 	Validate *validator.Validate
+	Pricing  model.PricingConfig
 }
 
 func NewUseCase(
 	repo repository.Store,
 	log *zap.Logger,
 	validate *validator.Validate,
+	pricing model.PricingConfig,
 ) *UseCase {
 	return &UseCase{
 		Repo:     repo,
 		Log:      log,
 		Validate: validate,
+		Pricing:  pricing,
 	}
 }
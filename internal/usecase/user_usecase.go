@@ -89,9 +89,17 @@ func (uc *UserSessionUsecase) Register(ctx context.Context, request *model.Regis
 		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to create user")
 	}
 
+	guest := uc.guestSession(ctx, request.GuestSessionID)
+	if err = uc.mergeGuestBookings(ctx, tx, guest, id); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to commit transaction")
 	}
+	// registering creates no session, the first login carries the state of the guest over
+	uc.rememberGuestSession(ctx, guest, id)
+	uc.retireGuestSession(ctx, guest)
 
 	uc.Log.Info("user registered successfully", zap.String("email", user.Email))
 	return nil
@@ -144,6 +152,11 @@ func (uc *UserSessionUsecase) Login(ctx context.Context, request *model.LoginUse
 		return nil, utils.WrapError(fiber.StatusUnauthorized, uc.Log, utils.Error, err, "invalid password")
 	}
 
+	guest := uc.guestSession(ctx, request.GuestSessionID)
+	if err = uc.mergeGuestBookings(ctx, tx, guest, user.ID); err != nil {
+		return nil, err
+	}
+
 	// generate session
 	session := &model.Session{
 		ID:           uuid.NewString(),
@@ -155,11 +168,17 @@ func (uc *UserSessionUsecase) Login(ctx context.Context, request *model.LoginUse
 		IsBlocked:    false,
 		ExpiresAt:    time.Now().Add(uc.config.GetDuration("Token.RefreshTokenDuration")),
 	}
+	if guest != nil {
+		session.GuestSessionID = guest.ID
+	} else {
+		session.GuestSessionID = uc.registeredGuestSession(ctx, user.ID)
+	}
 
 	// commit
 	if err := tx.Commit(ctx); err != nil {
 		return nil, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to commit transaction")
 	}
+	uc.retireGuestSession(ctx, guest)
 
 	// save session in redis
 	if err := uc.Repo.CreateSession(ctx, session); err != nil {
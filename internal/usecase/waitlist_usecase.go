@@ -0,0 +1,349 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"railway-go/internal/constant/model"
+	"railway-go/internal/repository"
+	"railway-go/internal/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+type WaitlistUC interface {
+	JoinWaitlist(ctx context.Context, req model.WaitlistRequest) (model.WaitlistResponse, error)
+	GetWaitlistEntry(ctx context.Context, id uuid.UUID) (model.WaitlistResponse, error)
+	LeaveWaitlist(ctx context.Context, id uuid.UUID) error
+}
+
+type WaitlistUsecase struct {
+	*UseCase
+}
+
+func NewWaitlistUsecase(useCase *UseCase) WaitlistUC {
+	return &WaitlistUsecase{UseCase: useCase}
+}
+
+// JoinWaitlist queues a passenger for a class of a schedule. Joining is only possible before
+// the schedule leaves the boarding station and while the class has no free seat for the
+// requested part of the route.
+func (uc *WaitlistUsecase) JoinWaitlist(ctx context.Context, req model.WaitlistRequest) (response model.WaitlistResponse, err error) {
+	if err := uc.Validate.Struct(req); err != nil {
+		return model.WaitlistResponse{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "validation failed")
+	}
+
+	tx, err := uc.Repo.BeginTransaction(ctx)
+	if err != nil {
+		return model.WaitlistResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to begin transaction")
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	schedule, err := tx.GetSchedule(ctx, req.ScheduleID)
+	if err != nil {
+		return model.WaitlistResponse{}, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed fetch schedule")
+	}
+
+	passenger, err := tx.GetPassenger(ctx, req.PassengerID)
+	if err != nil {
+		return model.WaitlistResponse{}, utils.WrapError(fiber.StatusBadRequest, uc.Log, utils.Warn, err, "no passenger found for the provided passenger ID")
+	}
+	if err = uc.authorizePassenger(ctx, passenger); err != nil {
+		return model.WaitlistResponse{}, err
+	}
+
+	segment, err := uc.resolveSegment(ctx, tx, schedule, req.FromStation, req.ToStation)
+	if err != nil {
+		return model.WaitlistResponse{}, err
+	}
+	if segment.departure(schedule).Before(time.Now()) {
+		return model.WaitlistResponse{}, fiber.NewError(fiber.StatusBadRequest, "schedule has already departed from the boarding station")
+	}
+
+	_, found, err := uc.findFreeSeat(ctx, tx, schedule.ID, repository.TipeClass(req.ClassType), segment)
+	if err != nil {
+		return model.WaitlistResponse{}, err
+	}
+	if found {
+		return model.WaitlistResponse{}, fiber.NewError(fiber.StatusConflict, "seats of this class are still available, book one directly")
+	}
+
+	entry, err := tx.CreateWaitlistEntry(ctx, repository.CreateWaitlistEntryParams{
+		PassengerID: req.PassengerID,
+		ScheduleID:  schedule.ID,
+		ClassType:   repository.TipeClass(req.ClassType),
+		FromStop:    segment.From,
+		ToStop:      segment.To,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return model.WaitlistResponse{}, utils.WrapError(fiber.StatusConflict, uc.Log, utils.Warn, err, "passenger is already waiting for this schedule")
+		}
+		return model.WaitlistResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to join waitlist")
+	}
+
+	position, err := tx.GetWaitlistPosition(ctx, entry.ID)
+	if err != nil {
+		return model.WaitlistResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to get waitlist position")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.WaitlistResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to commit transaction")
+	}
+
+	uc.Log.Info("passenger joined waitlist", zap.String("entry_id", entry.ID.String()), zap.Int64("schedule_id", schedule.ID), zap.Int64("position", position))
+	response = toWaitlistResponse(entry)
+	response.Position = position
+	return response, nil
+}
+
+// GetWaitlistEntry returns an entry with its current place in the queue while it is still waiting.
+func (uc *WaitlistUsecase) GetWaitlistEntry(ctx context.Context, id uuid.UUID) (response model.WaitlistResponse, err error) {
+	tx, err := uc.Repo.BeginTransaction(ctx)
+	if err != nil {
+		return model.WaitlistResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to begin transaction")
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	entry, err := tx.GetWaitlistEntry(ctx, id)
+	if err != nil {
+		return model.WaitlistResponse{}, utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed to get waitlist entry")
+	}
+
+	if _, err = uc.authorizePassengerID(ctx, tx, entry.PassengerID); err != nil {
+		return model.WaitlistResponse{}, err
+	}
+
+	response = toWaitlistResponse(entry)
+	if entry.Status == repository.WaitlistStatusWaiting {
+		response.Position, err = tx.GetWaitlistPosition(ctx, entry.ID)
+		if err != nil {
+			return model.WaitlistResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to get waitlist position")
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.WaitlistResponse{}, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to commit transaction")
+	}
+
+	return response, nil
+}
+
+func (uc *WaitlistUsecase) LeaveWaitlist(ctx context.Context, id uuid.UUID) (err error) {
+	tx, err := uc.Repo.BeginTransaction(ctx)
+	if err != nil {
+		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to begin transaction")
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	entry, err := tx.GetWaitlistEntry(ctx, id)
+	if err != nil {
+		return utils.WrapError(fiber.StatusNotFound, uc.Log, utils.Warn, err, "failed to get waitlist entry")
+	}
+
+	if _, err = uc.authorizePassengerID(ctx, tx, entry.PassengerID); err != nil {
+		return err
+	}
+
+	if entry.Status != repository.WaitlistStatusWaiting {
+		return fiber.NewError(fiber.StatusConflict, "waitlist entry is already "+string(entry.Status))
+	}
+
+	if err = tx.CancelWaitlistEntry(ctx, entry.ID); err != nil {
+		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to leave waitlist")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Error, err, "failed to commit transaction")
+	}
+
+	uc.Log.Info("passenger left waitlist", zap.String("entry_id", entry.ID.String()))
+	return nil
+}
+
+// promoteWaitlist hands the seats released on a schedule to the passengers waiting for it.
+// It is called after a cancellation, refund or expiry has been committed, so a failure here is
+// only logged and never undoes the release that triggered it. The released seat carries no
+// lock anymore, the lock of its booking was dropped when the reservation was committed.
+func (uc *UseCase) promoteWaitlist(ctx context.Context, scheduleID int64) {
+	// the promotion is made by the system, not by whoever released the seat
+	promoted, err := uc.promoteWaitlistEntries(utils.WithoutSession(ctx), scheduleID)
+	if err != nil {
+		uc.Log.Warn("failed to promote waitlist", zap.Int64("schedule_id", scheduleID), zap.Error(err))
+		return
+	}
+	if promoted > 0 {
+		uc.Log.Info("waitlist promoted", zap.Int64("schedule_id", scheduleID), zap.Int("reservations", promoted))
+	}
+}
+
+// promoteWaitlistEntries turns waiting entries into pending reservations, first come first
+// served within each class: once the oldest entry of a class cannot be seated, later entries
+// of that class keep waiting too. Every promoted passenger gets a payment deadline of its own.
+func (uc *UseCase) promoteWaitlistEntries(ctx context.Context, scheduleID int64) (promoted int, err error) {
+	tx, err := uc.Repo.BeginTransaction(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	// locked rows are being promoted by a concurrent run and are skipped
+	entries, err := tx.ListWaitingEntries(ctx, scheduleID)
+	if err != nil {
+		return 0, err
+	}
+
+	schedule, err := tx.GetSchedule(ctx, scheduleID)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(entries) == 0 || schedule.DepartureDate.Time.Before(time.Now()) {
+		return 0, tx.Commit(ctx)
+	}
+
+	route, err := uc.resolveSegment(ctx, tx, schedule, "", "")
+	if err != nil {
+		return 0, err
+	}
+
+	blocked := make(map[repository.TipeClass]bool)
+	for _, entry := range entries {
+		if blocked[entry.ClassType] {
+			continue
+		}
+
+		segment := journeySegment{From: entry.FromStop, To: entry.ToStop, Legs: route.Legs}
+		seat, found, err := uc.findFreeSeat(ctx, tx, schedule.ID, entry.ClassType, segment)
+		if err != nil {
+			return promoted, err
+		}
+		if !found {
+			blocked[entry.ClassType] = true
+			continue
+		}
+
+		passenger, err := tx.GetPassenger(ctx, entry.PassengerID)
+		if err != nil {
+			return promoted, err
+		}
+		price := uc.fare(schedule, entry.ClassType, segment, passenger.PassengerType, 0).Total
+		now := time.Now()
+		expiresAt := pgtype.Timestamp{Time: now.Add(model.ReservationTTL), Valid: true}
+
+		order, err := uc.createOrder(ctx, tx, price, expiresAt)
+		if err != nil {
+			return promoted, err
+		}
+
+		reserve, err := tx.CreateReservation(ctx, repository.CreateReservationParams{
+			PassengerID:       entry.PassengerID,
+			ScheduleID:        schedule.ID,
+			WagonID:           seat.WagonID,
+			SeatID:            seat.SeatID,
+			BookingDate:       pgtype.Timestamp{Time: now, Valid: true},
+			ReservationStatus: repository.StatusReservationPending,
+			DiscountID:        pgtype.UUID{Valid: false},
+			Price:             &price,
+			ExpiresAt:         expiresAt,
+			OrderID:           order.ID,
+			FromStop:          segment.From,
+			ToStop:            segment.To,
+			PassengerType:     passenger.PassengerType,
+		})
+		if err != nil {
+			return promoted, err
+		}
+
+		if err := uc.recordReservationCreated(ctx, tx, reserve, reasonPromoted); err != nil {
+			return promoted, err
+		}
+
+		if err := tx.PromoteWaitlistEntry(ctx, repository.PromoteWaitlistEntryParams{
+			ID:            entry.ID,
+			ReservationID: utils.ToPgUUID(reserve.ID),
+		}); err != nil {
+			return promoted, err
+		}
+
+		uc.Log.Info("waitlist entry promoted",
+			zap.String("entry_id", entry.ID.String()),
+			zap.String("reservation_id", reserve.ID.String()),
+			zap.String("booking_code", order.BookingCode),
+		)
+		promoted++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return promoted, nil
+}
+
+// findFreeSeat returns the first seat of the class that is neither reserved for an overlapping
+// part of the route nor locked by a booking in progress or a seat hold.
+func (uc *UseCase) findFreeSeat(ctx context.Context, tx repository.Transaction, scheduleID int64, class repository.TipeClass, segment journeySegment) (repository.SeatRef, bool, error) {
+	rows, err := tx.ListScheduleSeats(ctx, repository.ListScheduleSeatsParams{
+		FromStop:   segment.From,
+		ToStop:     segment.To,
+		ScheduleID: scheduleID,
+	})
+	if err != nil {
+		return repository.SeatRef{}, false, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to list schedule seats")
+	}
+
+	refs := make([]repository.SeatRef, 0, len(rows))
+	for _, row := range rows {
+		if row.ClassType == class {
+			refs = append(refs, repository.SeatRef{WagonID: row.WagonID, SeatID: row.SeatID})
+		}
+	}
+
+	locked, err := uc.Repo.LockedSeats(ctx, scheduleID, refs)
+	if err != nil {
+		return repository.SeatRef{}, false, utils.WrapError(fiber.StatusInternalServerError, uc.Log, utils.Warn, err, "failed to look up seat locks")
+	}
+
+	for _, row := range rows {
+		ref := repository.SeatRef{WagonID: row.WagonID, SeatID: row.SeatID}
+		if row.ClassType == class && seatState(row, locked[ref]) == model.SeatStateFree {
+			return ref, true, nil
+		}
+	}
+	return repository.SeatRef{}, false, nil
+}
+
+func toWaitlistResponse(entry repository.WaitlistEntry) model.WaitlistResponse {
+	return model.WaitlistResponse{
+		ID:            entry.ID,
+		PassengerID:   entry.PassengerID,
+		ScheduleID:    entry.ScheduleID,
+		ClassType:     string(entry.ClassType),
+		FromStop:      entry.FromStop,
+		ToStop:        entry.ToStop,
+		Status:        string(entry.Status),
+		ReservationID: entry.ReservationID,
+		CreatedAt:     entry.CreatedAt,
+	}
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+// bookingCodeAlphabet leaves out characters that are easily confused over the phone (0/O, 1/I).
+// The backfill in db/migration/03_booking_codes.up.sql draws from the same characters.
+const bookingCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const BookingCodeLength = 6
+
+// GenerateBookingCode returns a random booking reference such as "K7QX2M".
+func GenerateBookingCode() (string, error) {
+	code := make([]byte, BookingCodeLength)
+	max := big.NewInt(int64(len(bookingCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = bookingCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
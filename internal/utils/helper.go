@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"railway-go/internal/constant/model"
 
@@ -72,3 +73,12 @@ func HandleError(ctx *fiber.Ctx, log *zap.Logger, err error, statusCode int, msg
 	log.Warn(msg, zap.Error(err))
 	return ctx.Status(statusCode).JSON(model.BuildErrorResponse(msg))
 }
+
+// StatusCode returns the HTTP status carried by a *fiber.Error, or fallback for any other error.
+func StatusCode(err error, fallback int) int {
+	var e *fiber.Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return fallback
+}
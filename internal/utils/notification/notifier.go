@@ -0,0 +1,29 @@
+package notification
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// Notifier delivers short messages to customers by email or SMS.
+type Notifier interface {
+	Send(ctx context.Context, recipient, subject, message string) error
+}
+
+// logNotifier stands in for an email or SMS provider, messages are only written to the log.
+// The log then holds every message in full, verification codes included, so anyone reading
+// it can retrieve bookings. Replace it with a real provider outside development.
+type logNotifier struct {
+	log *zap.Logger
+}
+
+// NewLogNotifier creates a notifier that logs every message instead of delivering it.
+func NewLogNotifier(log *zap.Logger) Notifier {
+	return &logNotifier{log: log}
+}
+
+func (n *logNotifier) Send(ctx context.Context, recipient, subject, message string) error {
+	n.log.Info("notification sent", zap.String("recipient", recipient), zap.String("subject", subject), zap.String("message", message))
+	return nil
+}
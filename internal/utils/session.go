@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"context"
+	"railway-go/internal/constant/model"
+)
+
+type sessionKey struct{}
+
+// WithSession attaches the session of the current request to ctx
+func WithSession(ctx context.Context, session *model.Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+// SessionFrom returns the session attached to ctx, background jobs run without one
+func SessionFrom(ctx context.Context) (*model.Session, bool) {
+	session, ok := ctx.Value(sessionKey{}).(*model.Session)
+	return session, ok && session != nil
+}
+
+// WithoutSession detaches the session of the current request from ctx, for work done on
+// behalf of the system while handling it
+func WithoutSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionKey{}, (*model.Session)(nil))
+}
+
+// ActorFrom describes who acts through ctx, changes without a session are made by the system
+func ActorFrom(ctx context.Context) string {
+	if session, ok := SessionFrom(ctx); ok {
+		return session.Actor()
+	}
+	return model.ActorSystem
+}
@@ -0,0 +1,82 @@
+package ticket
+
+import (
+	"bytes"
+	"fmt"
+	"railway-go/internal/constant/model"
+	"strconv"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/skip2/go-qrcode"
+)
+
+const dateLayout = "Mon, 02 Jan 2006 15:04"
+
+// RenderPDF lays out the e-ticket on one A5 page with its QR code.
+func RenderPDF(ticket model.Ticket) ([]byte, error) {
+	qr, err := qrcode.Encode(ticket.QRCode, qrcode.Medium, 512)
+	if err != nil {
+		return nil, fmt.Errorf("encode qr code: %w", err)
+	}
+
+	pdf := gofpdf.New("L", "mm", "A5", "")
+	pdf.SetTitle("E-Ticket "+ticket.BookingCode, true)
+	pdf.SetMargins(12, 12, 12)
+	pdf.SetAutoPageBreak(false, 12)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(120, 10, "E-Ticket", "", 0, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.CellFormat(0, 10, "Booking code "+ticket.BookingCode, "", 1, "R", false, 0, "")
+	pdf.Line(12, pdf.GetY()+1, 198, pdf.GetY()+1)
+	pdf.Ln(5)
+
+	row := func(label, value string) {
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.SetTextColor(110, 110, 110)
+		pdf.CellFormat(40, 6, label, "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.CellFormat(90, 6, value, "", 1, "L", false, 0, "")
+	}
+	row("Passenger", ticket.PassengerName)
+	row("ID number", ticket.PassengerIDNumber)
+	row("Train", fmt.Sprintf("%s (%s)", ticket.TrainName, ticket.ClassType))
+	row("Seat", ticket.SeatNumber)
+	row("From", fmt.Sprintf("%s (%s)", ticket.From.Name, ticket.From.Code))
+	row("Departure", ticket.DepartureDate.Format(dateLayout))
+	row("To", fmt.Sprintf("%s (%s)", ticket.To.Name, ticket.To.Code))
+	row("Arrival", ticket.ArrivalDate.Format(dateLayout))
+	row("Price", formatRupiah(ticket.Price))
+
+	pdf.RegisterImageOptionsReader("qr", gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(qr))
+	pdf.ImageOptions("qr", 146, 30, 52, 52, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	pdf.SetXY(146, 84)
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.CellFormat(52, 4, ticket.ReservationID.String(), "", 1, "C", false, 0, "")
+
+	pdf.SetXY(12, 128)
+	pdf.SetFont("Helvetica", "I", 8)
+	pdf.SetTextColor(110, 110, 110)
+	pdf.MultiCell(0, 4, "Show this ticket with the identity document above when boarding. The ticket is only valid for the train, seat and stations printed on it.", "", "L", false)
+
+	var out bytes.Buffer
+	if err := pdf.Output(&out); err != nil {
+		return nil, fmt.Errorf("render ticket pdf: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// formatRupiah prints a price like "Rp 150.000".
+func formatRupiah(price int64) string {
+	digits := strconv.FormatInt(price, 10)
+	var grouped []byte
+	for i := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped = append(grouped, '.')
+		}
+		grouped = append(grouped, digits[i])
+	}
+	return "Rp " + string(grouped)
+}
@@ -0,0 +1,82 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"railway-go/internal/constant/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/o1egl/paseto"
+)
+
+// TicketSigner issues tickets as PASETO v2.public tokens. They are signed with an ed25519 key,
+// so conductor devices holding the public key can verify a ticket offline.
+type TicketSigner interface {
+	SignTicket(payload TicketPayload) (string, error)
+	VerifyTicket(token string) (*TicketPayload, error)
+	PublicKey() ed25519.PublicKey
+}
+
+// TicketPayload is what a ticket token vouches for: one seat between two stops of a schedule.
+type TicketPayload struct {
+	ReservationID uuid.UUID `json:"reservation_id"`
+	ScheduleID    int64     `json:"schedule_id"`
+	WagonID       int64     `json:"wagon_id"`
+	SeatID        int64     `json:"seat_id"`
+	FromStop      int32     `json:"from_stop"`
+	ToStop        int32     `json:"to_stop"`
+	IssuedAt      time.Time `json:"issued_at"`
+	ExpiredAt     time.Time `json:"expired_at"`
+}
+
+func (payload *TicketPayload) Valid() error {
+	if time.Now().After(payload.ExpiredAt) {
+		return model.ErrExpiredToken
+	}
+	return nil
+}
+
+type PasetoTicketSigner struct {
+	paseto     *paseto.V2
+	privateKey ed25519.PrivateKey
+}
+
+// NewTicketSigner creates a signer from a base64 encoded ed25519 seed of 32 bytes.
+func NewTicketSigner(seed string) (TicketSigner, error) {
+	key, err := base64.StdEncoding.DecodeString(seed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ticket key: %w", err)
+	}
+	if len(key) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid ticket key size: must be exactly %d bytes", ed25519.SeedSize)
+	}
+
+	return &PasetoTicketSigner{
+		paseto:     paseto.NewV2(),
+		privateKey: ed25519.NewKeyFromSeed(key),
+	}, nil
+}
+
+func (signer *PasetoTicketSigner) SignTicket(payload TicketPayload) (string, error) {
+	return signer.paseto.Sign(signer.privateKey, payload, nil)
+}
+
+func (signer *PasetoTicketSigner) VerifyTicket(token string) (*TicketPayload, error) {
+	payload := &TicketPayload{}
+
+	if err := signer.paseto.Verify(token, signer.PublicKey(), payload, nil); err != nil {
+		return nil, model.ErrInvalidToken
+	}
+
+	if err := payload.Valid(); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+func (signer *PasetoTicketSigner) PublicKey() ed25519.PublicKey {
+	return signer.privateKey.Public().(ed25519.PublicKey)
+}
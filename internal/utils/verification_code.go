@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+)
+
+const VerificationCodeLength = 6
+
+// GenerateVerificationCode returns a random one-time code of six digits such as "048213".
+func GenerateVerificationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", VerificationCodeLength, n.Int64()), nil
+}
+
+// HashVerificationCode is what is stored of a code. It only keeps the code from being read
+// at a glance, a six digit code is recovered from its hash by trying every code.
+func HashVerificationCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerificationCodeMatches compares a code with a stored hash in constant time.
+func VerificationCodeMatches(code, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashVerificationCode(code)), []byte(hash)) == 1
+}